@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maristcomputersociety/tuipardy/board"
+)
+
+// A question bank is a CSV file, or a directory of them, in the board format
+// but with any number of clues per category; the value column is the clue's
+// difficulty tier. "tuipardy generate" deals a board out of it, skipping clues
+// used by boards generated before.
+
+// questionBank is the clues of a bank, unused ones by category and tier
+type questionBank struct {
+	clues map[string]map[int][]*Question
+	total int
+}
+
+// loadBank reads a bank file, or every .csv file under a bank directory.
+// A clue found twice is kept once.
+func loadBank(path string) ([]*Question, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		files = nil
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.EqualFold(filepath.Ext(p), ".csv") {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no .csv files in %s", path)
+		}
+	}
+
+	var clues []*Question
+	seen := map[string]bool{}
+	for _, file := range files {
+		qs, err := board.LoadQuestions(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		for _, q := range qs {
+			if key := cardKey(q); !seen[key] {
+				seen[key] = true
+				clues = append(clues, q)
+			}
+		}
+	}
+	return clues, nil
+}
+
+// newQuestionBank groups the clues not in used
+func newQuestionBank(clues []*Question, used *usedHistory) *questionBank {
+	b := &questionBank{clues: map[string]map[int][]*Question{}}
+	for _, q := range clues {
+		if _, ok := used.Used[cardKey(q)]; ok {
+			continue
+		}
+		if b.clues[q.Category] == nil {
+			b.clues[q.Category] = map[int][]*Question{}
+		}
+		b.clues[q.Category][q.Value] = append(b.clues[q.Category][q.Value], q)
+		b.total++
+	}
+	return b
+}
+
+// generate deals a board: categories with an unused clue at every tier are
+// shuffled and the first ExpectedCategories taken, each with a random clue
+// per tier. The same seed, bank and history give the same board.
+func (b *questionBank) generate(tiers []int, seed uint64) (*Board, error) {
+	var names []string
+	for name, byTier := range b.clues {
+		full := true
+		for _, t := range tiers {
+			full = full && len(byTier[t]) > 0
+		}
+		if full {
+			names = append(names, name)
+		}
+	}
+	if len(names) < board.ExpectedCategories {
+		return nil, fmt.Errorf("%d categories have an unused clue at every value %s, need %d",
+			len(names), joinInts(tiers), board.ExpectedCategories)
+	}
+	sort.Strings(names)
+
+	rng := rand.New(rand.NewPCG(seed, 0))
+	rng.Shuffle(len(names), func(i, j int) { names[i], names[j] = names[j], names[i] })
+	names = names[:board.ExpectedCategories]
+	sort.Slice(names, func(i, j int) bool { return strings.ToLower(names[i]) < strings.ToLower(names[j]) })
+
+	out := &Board{}
+	for _, name := range names {
+		cat := &board.Category{Name: name}
+		for _, t := range tiers {
+			choices := b.clues[name][t]
+			q := *choices[rng.IntN(len(choices))]
+			cat.Questions = append(cat.Questions, &q)
+		}
+		out.Categories = append(out.Categories, cat)
+	}
+	if err := out.Validate(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// usedHistory records the clues put on generated boards
+type usedHistory struct {
+	Used map[string]string `json:"used"` // cardKey to the date it was used
+}
+
+// UsedHistoryPath returns the default generate --history file, shared by
+// every bank
+func UsedHistoryPath() string { return userDataPath("used.json") }
+
+func loadUsedHistory(path string) (*usedHistory, error) {
+	h := &usedHistory{Used: map[string]string{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("used clue history %s: %w", path, err)
+	}
+	if h.Used == nil {
+		h.Used = map[string]string{}
+	}
+	return h, nil
+}
+
+func (h *usedHistory) save(path string) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'))
+}
+
+// parseTiers reads a comma separated list of QuestionsPerCategory values
+func parseTiers(s string) ([]int, error) {
+	var tiers []int
+	for _, f := range strings.Split(s, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			return nil, fmt.Errorf("bad value %q: %w", f, err)
+		}
+		tiers = append(tiers, v)
+	}
+	if len(tiers) != board.QuestionsPerCategory {
+		return nil, fmt.Errorf("got %d values, expected %d", len(tiers), board.QuestionsPerCategory)
+	}
+	sort.Ints(tiers)
+	for i := 1; i < len(tiers); i++ {
+		if tiers[i] == tiers[i-1] {
+			return nil, fmt.Errorf("value %d given twice", tiers[i])
+		}
+	}
+	return tiers, nil
+}
+
+func joinInts(vs []int) string {
+	s := make([]string, len(vs))
+	for i, v := range vs {
+		s[i] = strconv.Itoa(v)
+	}
+	return strings.Join(s, ",")
+}
+
+// generateMain runs "tuipardy generate", returning the exit code
+func generateMain(args []string) int {
+	fl := flag.NewFlagSet("generate", flag.ExitOnError)
+	seed := fl.Uint64("seed", 0, "random seed, to generate the same board again (0 picks one and prints it)")
+	tierList := fl.String("tiers", "100,200,300", "comma separated clue values to take from the bank, one per row")
+	output := fl.String("o", "", "write the board to this file instead of stdout")
+	historyPath := fl.String("history", UsedHistoryPath(), "file recording the clues already used")
+	dryRun := fl.Bool("dry-run", false, "don't record the chosen clues as used")
+	fl.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s generate [flags] <bank.csv or directory>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Boards are always %d categories of %d clues, the shape the game lays out.\n", board.ExpectedCategories, board.QuestionsPerCategory)
+		fl.PrintDefaults()
+	}
+	fl.Parse(args)
+	if fl.NArg() != 1 {
+		fl.Usage()
+		return 2
+	}
+
+	tiers, err := parseTiers(*tierList)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error in --tiers: %v\n", err)
+		return 2
+	}
+	if *seed == 0 {
+		*seed = uint64(time.Now().UnixNano())
+	}
+	clues, err := loadBank(fl.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading question bank: %v\n", err)
+		return 1
+	}
+	used, err := loadUsedHistory(*historyPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading history: %v\n", err)
+		return 1
+	}
+
+	bank := newQuestionBank(clues, used)
+	b, err := bank.generate(tiers, *seed)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error generating board from %d unused of %d clues: %v\n", bank.total, len(clues), err)
+		return 1
+	}
+
+	var buf bytes.Buffer
+	if err := b.Write(&buf); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing board: %v\n", err)
+		return 1
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+	} else {
+		err = writeFileAtomic(*output, buf.Bytes())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error writing board: %v\n", err)
+		return 1
+	}
+
+	if !*dryRun {
+		today := time.Now().Format(studyDateLayout)
+		for _, cat := range b.Categories {
+			for _, q := range cat.Questions {
+				used.Used[cardKey(q)] = today
+			}
+		}
+		if err := used.save(*historyPath); err != nil {
+			fmt.Fprintf(os.Stderr, "error saving history: %v\n", err)
+			return 1
+		}
+	}
+	fmt.Fprintf(os.Stderr, "generated with --seed %d from %d unused of %d clues\n", *seed, bank.total, len(clues))
+	return 0
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testBank returns clues for cats categories, with perTier clues at each of
+// the values 100, 200 and 300
+func testBank(cats, perTier int) []*Question {
+	var clues []*Question
+	for c := range cats {
+		for _, v := range []int{100, 200, 300} {
+			for i := range perTier {
+				clues = append(clues, &Question{
+					Category: fmt.Sprintf("Cat %c", 'A'+c),
+					Value:    v,
+					Q:        fmt.Sprintf("Q %c %d %d", 'A'+c, v, i),
+					A:        "A",
+				})
+			}
+		}
+	}
+	return clues
+}
+
+// boardCSV writes b in the board format
+func boardCSV(t *testing.T, b *Board) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := b.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestGenerateSeed(t *testing.T) {
+	tiers := []int{100, 200, 300}
+	clues := testBank(10, 4)
+	none := &usedHistory{Used: map[string]string{}}
+	seen := map[string]bool{}
+	for _, seed := range []uint64{1, 2, 3, 42, 1 << 63} {
+		first, err := newQuestionBank(clues, none).generate(tiers, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		again, err := newQuestionBank(clues, none).generate(tiers, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a, b := boardCSV(t, first), boardCSV(t, again); a != b {
+			t.Errorf("seed %d gave two boards:\n%s\n%s", seed, a, b)
+		}
+		seen[boardCSV(t, first)] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("every seed gave the same board")
+	}
+}
+
+func TestGenerateSkipsUsed(t *testing.T) {
+	tiers := []int{100, 200, 300}
+	clues := testBank(7, 2)
+	tests := []struct {
+		name    string
+		used    func(q *Question) bool
+		wantErr string
+	}{
+		{"nothing used", func(*Question) bool { return false }, ""},
+		{"first clue of every tier", func(q *Question) bool { return strings.HasSuffix(q.Q, " 0") }, ""},
+		{"one category's 200s", func(q *Question) bool { return q.Category == "Cat A" && q.Value == 200 }, ""},
+		{"two categories' 300s", func(q *Question) bool { return q.Category <= "Cat B" && q.Value == 300 },
+			"5 categories have an unused clue at every value 100,200,300, need 6"},
+		{"everything", func(*Question) bool { return true }, "0 categories"},
+	}
+	for _, tt := range tests {
+		used := &usedHistory{Used: map[string]string{}}
+		for _, q := range clues {
+			if tt.used(q) {
+				used.Used[cardKey(q)] = "2026-01-01"
+			}
+		}
+		for seed := range uint64(20) {
+			b, err := newQuestionBank(clues, used).generate(tiers, seed)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("%s: error %v, want %q", tt.name, err, tt.wantErr)
+				}
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			for _, cat := range b.Categories {
+				for _, q := range cat.Questions {
+					if _, ok := used.Used[cardKey(q)]; ok {
+						t.Errorf("%s, seed %d: used clue %q dealt", tt.name, seed, q.Q)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSampleBankBoards(t *testing.T) {
+	clues, err := loadBank(filepath.Join("questions", "bank"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tiers := []int{100, 200, 300}
+	used := &usedHistory{Used: map[string]string{}}
+	boards := 0
+	for seed := uint64(1); ; seed++ {
+		b, err := newQuestionBank(clues, used).generate(tiers, seed)
+		if err != nil {
+			break
+		}
+		boards++
+		for _, cat := range b.Categories {
+			for _, q := range cat.Questions {
+				used.Used[cardKey(q)] = "2026-01-01"
+			}
+		}
+	}
+	if boards < 5 {
+		t.Errorf("the sample bank ran out after %d boards", boards)
+	}
+}
+
+func TestLoadBank(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.csv":       "Cat,100,Q1,A1\nCat,200,Q2,A2\n",
+		"sub/b.CSV":   "Cat,100,Q1,another answer\nOther,300,Q3,A3\n", // Q1 again
+		"notes.txt":   "not a bank",
+		"sub/c.csv~":  "Cat,100,backup,A\n",
+		"sub/d/e.csv": "Deep,100,Q4,A4\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		path    string
+		want    int
+		wantErr string
+	}{
+		{dir, 4, ""},
+		{filepath.Join(dir, "a.csv"), 2, ""},
+		{filepath.Join(dir, "sub", "d"), 1, ""},
+		{filepath.Join(dir, "missing"), 0, "no such file"},
+		{t.TempDir(), 0, "no .csv files"},
+		{filepath.Join("questions", "bank"), 120, ""},
+	}
+	for _, tt := range tests {
+		clues, err := loadBank(tt.path)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error %v, want %q", tt.path, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || len(clues) != tt.want {
+			t.Errorf("%s: %d clues, %v, want %d", tt.path, len(clues), err, tt.want)
+		}
+	}
+}
+
+func TestParseTiers(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr string
+	}{
+		{"100,200,300", "100,200,300", ""},
+		{" 300, 100 ,200", "100,200,300", ""},
+		{"100,200", "", "got 2 values, expected 3"},
+		{"100,200,300,400", "", "got 4 values"},
+		{"100,100,200", "", "value 100 given twice"},
+		{"100,x,300", "", `bad value "x"`},
+	}
+	for _, tt := range tests {
+		tiers, err := parseTiers(tt.in)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%q: error %v, want %q", tt.in, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || joinInts(tiers) != tt.want {
+			t.Errorf("%q: %v, %v, want %s", tt.in, tiers, err, tt.want)
+		}
+	}
+}
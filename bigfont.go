@@ -0,0 +1,68 @@
+package main
+
+import "github.com/gdamore/tcell/v2"
+
+// built-in block font for board values and scores
+const (
+	BigFontHeight  = 5
+	BigFontSpacing = 1 // blank columns between glyphs
+)
+
+var bigGlyphs = map[rune][BigFontHeight]string{
+	'0': {"███", "█ █", "█ █", "█ █", "███"},
+	'1': {" █ ", "██ ", " █ ", " █ ", "███"},
+	'2': {"███", "  █", "███", "█  ", "███"},
+	'3': {"███", "  █", " ██", "  █", "███"},
+	'4': {"█ █", "█ █", "███", "  █", "  █"},
+	'5': {"███", "█  ", "███", "  █", "███"},
+	'6': {"███", "█  ", "███", "█ █", "███"},
+	'7': {"███", "  █", "  █", "  █", "  █"},
+	'8': {"███", "█ █", "███", "█ █", "███"},
+	'9': {"███", "█ █", "███", "  █", "███"},
+	'$': {"▄█▄", "█  ", "▀█▄", "  █", "▀█▀"},
+	'-': {"   ", "   ", "███", "   ", "   "},
+	'+': {"   ", " █ ", "███", " █ ", "   "},
+	' ': {"  ", "  ", "  ", "  ", "  "},
+}
+
+// bigTextWidth returns the width of text in the big font, or -1 if some
+// character has no glyph
+func bigTextWidth(text string) int {
+	w := 0
+	for i, r := range []rune(text) {
+		g, ok := bigGlyphs[r]
+		if !ok {
+			return -1
+		}
+		if i > 0 {
+			w += BigFontSpacing
+		}
+		w += textWidth(g[0])
+	}
+	return w
+}
+
+// fitsBig reports whether text can be drawn in the big font within w×h cells
+func fitsBig(text string, w, h int) bool {
+	bw := bigTextWidth(text)
+	return bw >= 0 && bw <= w && h >= BigFontHeight
+}
+
+// drawBigText draws text in the big font with its top left corner at (x, y)
+func drawBigText(s tcell.Screen, x, y int, st tcell.Style, text string) {
+	for _, r := range text {
+		g, ok := bigGlyphs[r]
+		if !ok {
+			continue
+		}
+		for row, line := range g {
+			drawText(s, x, y+row, st, line)
+		}
+		x += textWidth(g[0]) + BigFontSpacing
+	}
+}
+
+// drawCenteredBigText draws text in the big font centered in a w×h area
+func drawCenteredBigText(s tcell.Screen, x, y, w, h int, st tcell.Style, text string) {
+	drawBigText(s, x+(w-bigTextWidth(text))/2, y+(h-BigFontHeight)/2, st, text)
+}
@@ -0,0 +1,105 @@
+// Package board is the tuipardy board model: categories of clues with point
+// values, and loaders and validators for board files.
+//
+// The exported API of this package and of package engine follows semantic
+// versioning: within a major version, exported names keep their meaning and
+// signatures, new fields and functions may be added, and nothing is removed.
+// See the "library" section of the README for the versioning policy.
+//
+// Loading and checking a board:
+//
+//	b, err := board.Load("questions/board.csv")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, cat := range b.Categories {
+//		fmt.Println(cat.Name, len(cat.Questions))
+//	}
+package board
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Question is one clue on the board
+type Question struct {
+	Category        string
+	Value           int
+	Q               string
+	A               string
+	ImagePath       string   // optional
+	AnswerImagePath string   // optional, replaces ImagePath once the answer is shown
+	Alternates      []string // optional, other answers accepted when responses are judged automatically
+	Picked          bool     // opened during play
+}
+
+// Image returns the image for the side of the clue being shown. The answer
+// falls back to the question image when it has none of its own, so boards
+// written before answer images keep their picture up through the reveal.
+func (q *Question) Image(answer bool) string {
+	if answer && q.AnswerImagePath != "" {
+		return q.AnswerImagePath
+	}
+	return q.ImagePath
+}
+
+// Category is one column of the board
+type Category struct {
+	Name      string
+	Questions []*Question // sorted by value
+}
+
+// Board is a full game board. Boards from Load and Read are valid; boards
+// built in code should be checked with Validate.
+type Board struct {
+	Categories []*Category // sorted by name, len == ExpectedCategories
+}
+
+// board shape
+const (
+	QuestionsPerCategory = 3
+	ExpectedCategories   = 6
+)
+
+// At returns the question in column col, row row
+func (b *Board) At(col, row int) *Question {
+	return b.Categories[col].Questions[row]
+}
+
+// Validate checks that the board has the expected shape and that every clue
+// has a question (text or image), an answer and a positive value. Loaded
+// boards have already been validated; call it on boards built by hand.
+func (b *Board) Validate() error {
+	if len(b.Categories) != ExpectedCategories {
+		return fmt.Errorf("expected %d categories, got %d", ExpectedCategories, len(b.Categories))
+	}
+	for _, cat := range b.Categories {
+		if strings.TrimSpace(cat.Name) == "" {
+			return fmt.Errorf("category with no name")
+		}
+		if len(cat.Questions) != QuestionsPerCategory {
+			return fmt.Errorf("category %q has %d questions, expected %d", cat.Name, len(cat.Questions), QuestionsPerCategory)
+		}
+		for _, q := range cat.Questions {
+			switch {
+			case q.Value <= 0:
+				return fmt.Errorf("category %q: value %d is not positive", cat.Name, q.Value)
+			case strings.TrimSpace(q.Q) == "" && q.ImagePath == "":
+				return fmt.Errorf("category %q, %d: no question text or image", cat.Name, q.Value)
+			case strings.TrimSpace(q.A) == "":
+				return fmt.Errorf("category %q, %d: empty answer", cat.Name, q.Value)
+			}
+		}
+	}
+	return nil
+}
+
+// Reset marks every clue as not yet picked
+func (b *Board) Reset() {
+	for _, cat := range b.Categories {
+		for _, q := range cat.Questions {
+			q.Picked = false
+		}
+	}
+}
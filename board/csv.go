@@ -0,0 +1,140 @@
+package board
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Load reads and validates a board CSV file. Each row is
+//
+//	category,value,question,answer[,image[,answer image[,alternates]]]
+//
+// where alternates are other accepted answers separated by "|".
+func Load(path string) (*Board, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Read reads and validates a board in CSV form, as described for Load
+func Read(in io.Reader) (*Board, error) {
+	qs, err := ReadQuestions(in)
+	if err != nil {
+		return nil, err
+	}
+	byCat := map[string][]*Question{}
+	for _, q := range qs {
+		byCat[q.Category] = append(byCat[q.Category], q)
+	}
+
+	cats := make([]*Category, 0, ExpectedCategories)
+	for cat, qs := range byCat {
+		sort.Slice(qs, func(i, j int) bool { return qs[i].Value < qs[j].Value })
+		cats = append(cats, &Category{Name: cat, Questions: qs})
+	}
+
+	sort.Slice(cats, func(i, j int) bool { return strings.ToLower(cats[i].Name) < strings.ToLower(cats[j].Name) })
+
+	b := &Board{Categories: cats}
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// LoadQuestions reads the rows of a CSV file in the board format, in file
+// order, without checking that they make up a board
+func LoadQuestions(path string) ([]*Question, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadQuestions(f)
+}
+
+// ReadQuestions reads CSV rows in the board format, as described for
+// LoadQuestions
+func ReadQuestions(in io.Reader) ([]*Question, error) {
+	r := csv.NewReader(in)
+	r.FieldsPerRecord = -1
+	r.ReuseRecord = true
+
+	var qs []*Question
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("csv read: %w", err)
+		}
+
+		if len(rec) < 4 {
+			return nil, fmt.Errorf("csv record has %d fields, expected at least 4", len(rec))
+		}
+
+		cat := strings.TrimSpace(rec[0])
+		val, err := strconv.Atoi(strings.TrimSpace(rec[1]))
+		if err != nil {
+			return nil, fmt.Errorf("bad value %q: %w", rec[1], err)
+		}
+		q := strings.TrimSpace(rec[2])
+		a := strings.TrimSpace(rec[3])
+
+		var imagePath, answerImagePath string
+		if len(rec) >= 5 {
+			imagePath = strings.TrimSpace(rec[4])
+		}
+		if len(rec) >= 6 {
+			answerImagePath = strings.TrimSpace(rec[5])
+		}
+		var alternates []string
+		if len(rec) >= 7 {
+			for _, alt := range strings.Split(rec[6], "|") {
+				if alt = strings.TrimSpace(alt); alt != "" {
+					alternates = append(alternates, alt)
+				}
+			}
+		}
+
+		qs = append(qs, &Question{
+			Category:        cat,
+			Value:           val,
+			Q:               q,
+			A:               a,
+			ImagePath:       imagePath,
+			AnswerImagePath: answerImagePath,
+			Alternates:      alternates,
+		})
+	}
+	return qs, nil
+}
+
+// Write writes a board in the CSV form read by Read, leaving out empty
+// optional columns at the end of each row
+func (b *Board) Write(out io.Writer) error {
+	w := csv.NewWriter(out)
+	for _, cat := range b.Categories {
+		for _, q := range cat.Questions {
+			rec := []string{q.Category, strconv.Itoa(q.Value), q.Q, q.A,
+				q.ImagePath, q.AnswerImagePath, strings.Join(q.Alternates, "|")}
+			for len(rec) > 4 && rec[len(rec)-1] == "" {
+				rec = rec[:len(rec)-1]
+			}
+			if err := w.Write(rec); err != nil {
+				return err
+			}
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
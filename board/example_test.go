@@ -0,0 +1,97 @@
+package board_test
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/maristcomputersociety/tuipardy/board"
+)
+
+// sampleCSV is a full board: six categories of three clues
+var sampleCSV = func() string {
+	var b strings.Builder
+	for _, cat := range []string{"Go", "Unix", "Networks", "Databases", "Compilers", "History"} {
+		for _, v := range []int{100, 200, 300} {
+			fmt.Fprintf(&b, "%s,%d,A %s question for %d,An answer\n", cat, v, cat, v)
+		}
+	}
+	return b.String()
+}()
+
+func ExampleRead() {
+	b, err := board.Read(strings.NewReader(sampleCSV))
+	if err != nil {
+		log.Fatal(err)
+	}
+	// categories are sorted by name, clues by value
+	for _, cat := range b.Categories {
+		fmt.Println(cat.Name, cat.Questions[0].Value, cat.Questions[2].Value)
+	}
+	fmt.Println(b.At(1, 2).Q)
+	// Output:
+	// Compilers 100 300
+	// Databases 100 300
+	// Go 100 300
+	// History 100 300
+	// Networks 100 300
+	// Unix 100 300
+	// A Databases question for 300
+}
+
+func ExampleRead_alternates() {
+	csv := strings.Replace(sampleCSV, "Go,100,A Go question for 100,An answer",
+		`Go,100,"This browser grew out of Phoenix",Firefox,,,Phoenix|Mozilla Firefox`, 1)
+	b, err := board.Read(strings.NewReader(csv))
+	if err != nil {
+		log.Fatal(err)
+	}
+	q := b.Categories[2].Questions[0]
+	fmt.Printf("%s: %q\n", q.A, q.Alternates)
+	// Output:
+	// Firefox: ["Phoenix" "Mozilla Firefox"]
+}
+
+func ExampleBoard_Validate() {
+	b, err := board.Read(strings.NewReader(sampleCSV))
+	if err != nil {
+		log.Fatal(err)
+	}
+	b.At(0, 0).A = ""
+	fmt.Println(b.Validate())
+	b.Categories = b.Categories[:5]
+	fmt.Println(b.Validate())
+	// Output:
+	// category "Compilers", 100: empty answer
+	// expected 6 categories, got 5
+}
+
+func ExampleBoard_Write() {
+	b := &board.Board{}
+	for _, name := range []string{"A", "B", "C", "D", "E", "F"} {
+		cat := &board.Category{Name: name}
+		for _, v := range []int{100, 200, 300} {
+			cat.Questions = append(cat.Questions, &board.Question{Category: name, Value: v, Q: "Q, with a comma", A: "A"})
+		}
+		b.Categories = append(b.Categories, cat)
+	}
+	b.At(0, 0).ImagePath = "questions/images/a.png"
+	var out strings.Builder
+	if err := b.Write(&out); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(strings.Join(strings.SplitAfter(out.String(), "\n")[:2], ""))
+	// Output:
+	// A,100,"Q, with a comma",A,questions/images/a.png
+	// A,200,"Q, with a comma",A
+}
+
+func ExampleQuestion_Image() {
+	q := &board.Question{ImagePath: "tux.png"}
+	fmt.Println(q.Image(false), q.Image(true))
+	q.AnswerImagePath = "beastie.png"
+	fmt.Println(q.Image(false), q.Image(true))
+	// Output:
+	// tux.png tux.png
+	// tux.png beastie.png
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// clueView draws the question screen for one clue: its category and value,
+// its text scaled to fit and scrolled when it overflows, and its image. A
+// game embeds one, and so does study mode.
+type clueView struct {
+	s              tcell.Screen
+	keys           Keymap
+	pt             *Passthrough // raw escape output kept in step with tcell
+	imageRenderer  *ImageRenderer
+	imageSupported bool
+	textSizing     bool // terminal supports kitty text sizing (OSC 66)
+	clueScroll     int  // first clue line shown when the text overflows
+	clueMaxScroll  int
+	cluePage       int // clue lines visible at once, for PgUp/PgDn
+}
+
+// newClueView sets up image support for the images setting. keys name the
+// scroll keys in the hints.
+func newClueView(images string, keys Keymap) clueView {
+	v := clueView{keys: keys, textSizing: IsTextSizingSupported()}
+	switch images {
+	case ImagesAuto:
+		v.imageSupported = IsImageSupported()
+	case ImagesKitty:
+		v.imageSupported = true
+	}
+	if v.imageSupported {
+		v.imageRenderer = NewImageRenderer()
+	}
+	return v
+}
+
+// setScreen points the view at an initialized screen
+func (v *clueView) setScreen(s tcell.Screen) {
+	v.s = s
+	v.pt = NewPassthrough(s)
+}
+
+// drawClue renders the question screen for q, showing its answer side when
+// answer is set
+func (v *clueView) drawClue(q *Question, answer bool) {
+	s := v.s
+	w, h := s.Size()
+	if q == nil {
+		return
+	}
+
+	v.drawQuestionBackground(s, w, h)
+	v.drawQuestionTitle(s, w, q, answer)
+	v.drawQuestionSeparator(s, w)
+	v.drawQuestionContent(s, w, h, q, answer)
+}
+
+// drawQuestionBackground fills and draws the background box for the question screen
+func (v *clueView) drawQuestionBackground(s tcell.Screen, w, h int) {
+	fillBox(s, 0, 0, w, h-StatusBarHeight, styleQuestion())
+	drawBox(s, 0, 0, w, h-StatusBarHeight, styleQuestion())
+}
+
+// drawQuestionTitle renders the category and value at the top of the question screen
+func (v *clueView) drawQuestionTitle(s tcell.Screen, w int, q *Question, answer bool) {
+	title := fmt.Sprintf("%s — $%d", q.Category, q.Value)
+	if answer {
+		// say so in words, not just with the answer color
+		title += " — ANSWER"
+	}
+	drawCenteredText(s, 0, 3, w, 1, styleQuestion().Bold(true), title)
+}
+
+// drawQuestionSeparator draws a horizontal separator line below the title
+func (v *clueView) drawQuestionSeparator(s tcell.Screen, w int) {
+	separatorY := 5
+	for x := w / 4; x < 3*w/4; x++ {
+		setCell(s, x, separatorY, '─', styleQuestion())
+	}
+}
+
+// drawQuestionContent renders the main question/answer text with optional image
+func (v *clueView) drawQuestionContent(s tcell.Screen, w, h int, q *Question, answer bool) {
+	questionAreaY := QuestionAreaY
+	questionAreaH := h - questionAreaY - 3
+
+	textToShow, textStyle := q.Q, styleQuestion().Bold(true)
+	if answer {
+		textToShow, textStyle = q.A, styleAnswer().Bold(true)
+	}
+
+	if path := q.Image(answer); path != "" && v.imageSupported && v.imageRenderer != nil && v.pt.Enabled() {
+		// Use horizontal split: top 65% for image, bottom 35% for text
+		v.drawQuestionWithImage(s, w, questionAreaY, questionAreaH, path, textToShow, textStyle)
+	} else {
+		// Full screen for text when no image
+		v.drawQuestionFullWidth(s, w, questionAreaY, questionAreaH, textToShow, textStyle)
+	}
+}
+
+// drawQuestionWithImage renders question text below an image in a horizontal split
+func (v *clueView) drawQuestionWithImage(s tcell.Screen, w, questionAreaY, questionAreaH int, imagePath, textToShow string, textStyle tcell.Style) {
+	// split horizontally: top 65% for image, bottom 35% for text
+	imageHeight := questionAreaH * ImageHeightRatio / 100
+	textHeight := questionAreaH - imageHeight
+	textY := questionAreaY + imageHeight
+
+	textPadding := ImageTextPadding
+	adjustedTextY := textY + textPadding
+	adjustedTextHeight := textHeight - textPadding
+
+	if adjustedTextHeight < 1 {
+		adjustedTextHeight = 1
+		adjustedTextY = textY
+	}
+
+	clearTextArea(s, 0, adjustedTextY, w, adjustedTextHeight, styleQuestion())
+
+	v.queueImage(imagePath, 0, questionAreaY, w, imageHeight)
+	v.drawClueText(s, 0, adjustedTextY, w, adjustedTextHeight, textToShow, textStyle)
+}
+
+// drawQuestionFullWidth renders question text across the full width of the screen
+func (v *clueView) drawQuestionFullWidth(s tcell.Screen, w, questionAreaY, questionAreaH int, textToShow string, textStyle tcell.Style) {
+	clearTextArea(s, 0, questionAreaY, w, questionAreaH, styleQuestion())
+
+	v.drawClueText(s, 0, questionAreaY, w, questionAreaH, textToShow, textStyle)
+}
+
+// queueImage centers an image in the given area using the Kitty protocol
+func (v *clueView) queueImage(imagePath string, x, y, w, h int) {
+	if v.imageRenderer == nil || imagePath == "" {
+		return
+	}
+
+	// define the image area boundaries
+	imageAreaY := y
+	imageAreaHeight := h
+	imageAreaWidth := w - 4 // leave some padding on sides
+
+	// calculate the midpoint of the designated image area
+	areaMidX := x + w/2
+	areaMidY := imageAreaY + imageAreaHeight/2
+
+	imgWidth, imgHeight, err := v.imageRenderer.GetImageBounds(imagePath)
+	if err != nil {
+		return
+	}
+
+	estimatedCellWidth := imgWidth / 10
+	estimatedCellHeight := imgHeight / 20
+
+	// if estimated size exceeds the available area, scale down proportionally
+	if estimatedCellWidth > imageAreaWidth {
+		scale := float64(imageAreaWidth) / float64(estimatedCellWidth)
+		estimatedCellWidth = imageAreaWidth
+		estimatedCellHeight = int(float64(estimatedCellHeight) * scale)
+	}
+	if estimatedCellHeight > imageAreaHeight {
+		scale := float64(imageAreaHeight) / float64(estimatedCellHeight)
+		estimatedCellHeight = imageAreaHeight
+		estimatedCellWidth = int(float64(estimatedCellWidth) * scale)
+	}
+	if estimatedCellWidth < 1 || estimatedCellHeight < 1 {
+		return
+	}
+
+	// calculate cursor position to center the image's midpoint on the area's midpoint
+	cursorX := areaMidX - (estimatedCellWidth / 2)
+	cursorY := areaMidY - (estimatedCellHeight / 2)
+
+	if cursorX < x+2 {
+		cursorX = x + 2
+	}
+	if cursorY < imageAreaY {
+		cursorY = imageAreaY
+	}
+	if cursorX+estimatedCellWidth > x+w-2 { // ensure image doesn't go off right edge
+		cursorX = x + w - 2 - estimatedCellWidth
+	}
+	if cursorY+estimatedCellHeight > imageAreaY+imageAreaHeight { // ensure image doesn't go off bottom
+		cursorY = imageAreaY + imageAreaHeight - estimatedCellHeight
+	}
+
+	imageData, err := v.imageRenderer.RenderImageToString(imagePath, estimatedCellWidth, estimatedCellHeight)
+	if err != nil || imageData == "" {
+		return
+	}
+
+	// a=d drops the placement once the image is no longer wanted
+	v.pt.Queue(cursorX, cursorY, estimatedCellWidth, estimatedCellHeight, imageData, "\x1b_Ga=d\x1b\\")
+}
+
+// fitClue lays out clue text at the largest scale that fits the area: 2x
+// when the terminal supports Kitty text sizing, otherwise (or when 2x is too
+// tall) 1x, which scrolls if it still doesn't fit
+func (v *clueView) fitClue(text string, w, h int) (scale int, lines []clueLine) {
+	// layout happens in unscaled columns, leaving a margin on both sides
+	if v.textSizing && v.pt.Enabled() {
+		lines = layoutClue(text, w/2-4)
+		if len(lines)*2 <= h {
+			return 2, lines
+		}
+	}
+	return 1, layoutClue(text, w-4)
+}
+
+// drawClueText renders clue text centered in the given area, scaled to fit.
+// Text that doesn't fit even at 1x scrolls with PgUp/PgDn, with "more"
+// markers on the first and last rows of the area.
+func (v *clueView) drawClueText(s tcell.Screen, x, y, w, h int, text string, st tcell.Style) {
+	scale, lines := v.fitClue(text, w, h)
+	areaW := w/scale - 4
+	highlight := s.Colors() >= 16
+	offsets := lineOffsets(lines, areaW)
+
+	// rows available for text, minus the marker rows when scrolling
+	rows := h / scale
+	v.clueMaxScroll = 0
+	if len(lines) > rows && h > 2 {
+		rows = h - 2
+		v.clueMaxScroll = len(lines) - rows
+	}
+	v.cluePage = max(1, rows)
+	v.clueScroll = min(max(v.clueScroll, 0), v.clueMaxScroll)
+
+	startY := y + (h-len(lines)*scale)/2
+	if v.clueMaxScroll > 0 {
+		startY = y + 1
+		if v.clueScroll > 0 {
+			drawCenteredText(s, x, y, w, 1, styleMarker(), fmt.Sprintf("▲ more (%s)", v.keys.Names(ActionScrollUp)))
+		}
+		if v.clueScroll < v.clueMaxScroll {
+			drawCenteredText(s, x, y+h-1, w, 1, styleMarker(), fmt.Sprintf("▼ more (%s)", v.keys.Names(ActionScrollDown)))
+		}
+		lines = lines[v.clueScroll : v.clueScroll+rows]
+		offsets = offsets[v.clueScroll : v.clueScroll+rows]
+	}
+	if startY < y {
+		startY = y
+	}
+
+	for i, line := range lines {
+		lineY := startY + i*scale
+		if lineY >= y+h {
+			break
+		}
+		cx := x + (2+offsets[i])*scale
+
+		if scale == 1 {
+			for _, sp := range line.spans {
+				cx = drawText(s, cx, lineY, styleSpan(st, sp, highlight), sp.text)
+			}
+			continue
+		}
+
+		if line.width == 0 {
+			continue
+		}
+
+		// kitty text scaling, one sized run per span
+		var data strings.Builder
+		for _, sp := range line.spans {
+			data.WriteString(sgr(styleSpan(st, sp, highlight), s.Colors()))
+			data.WriteString("\x1b]66;s=2;" + sp.text + "\x07")
+		}
+		data.WriteString("\x1b[0m")
+		v.pt.Queue(cx, lineY, line.width*scale, scale, data.String(), "")
+	}
+}
+
+// scrollClue moves the clue text by n lines, clamped to what is scrollable
+func (v *clueView) scrollClue(n int) {
+	v.clueScroll = min(max(v.clueScroll+n, 0), v.clueMaxScroll)
+}
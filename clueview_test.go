@@ -0,0 +1,31 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestScrollHints(t *testing.T) {
+	cfg := testConfig("Red", "Blue")
+	cfg.Keys = map[string][]string{ActionScrollUp: {"p"}, ActionScrollDown: {"n"}}
+	g, err := NewGame(loadSampleBoard(t), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := NewHarness(g, 30, 13)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	h.Key(tcell.KeyEnter, 0)
+	if snap := h.Snapshot(); !strings.Contains(snap, "▼ more (n)") || strings.Contains(snap, "▲") {
+		t.Fatalf("top of a long clue:\n%s", snap)
+	}
+	h.Type("n")
+	if snap := h.Snapshot(); !strings.Contains(snap, "▲ more (p)") {
+		t.Errorf("after scrolling down:\n%s", snap)
+	}
+}
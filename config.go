@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/maristcomputersociety/tuipardy/engine"
+)
+
+// Config holds the user's defaults. It is read from the user config file,
+// then from a config file next to the board, then overridden by flags.
+type Config struct {
+	Teams    []string            `toml:"teams"` // skip team setup when set
+	MinTeams int                 `toml:"min_teams"`
+	MaxTeams int                 `toml:"max_teams"`
+	Theme    string              `toml:"theme"`
+	Images   string              `toml:"images"` // auto, kitty or none
+	Timers   TimerConfig         `toml:"timers"`
+	Keys     map[string][]string `toml:"keys"` // action name -> key names
+	Rules    RulesConfig         `toml:"rules"`
+
+	themeSet bool // a config file set the theme
+}
+
+type TimerConfig struct {
+	Clue Duration `toml:"clue"` // countdown shown on the question screen, 0 for none
+}
+
+type RulesConfig struct {
+	NegativeScores bool `toml:"negative_scores"` // let scores drop below zero
+	ReopenPicked   bool `toml:"reopen_picked"`   // allow opening taken clues again
+}
+
+// Duration is a time.Duration written as "30s" in config files
+type Duration struct{ time.Duration }
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+// image protocol settings
+const (
+	ImagesAuto  = "auto"
+	ImagesKitty = "kitty"
+	ImagesNone  = "none"
+)
+
+// DefaultConfig returns the settings used when no config file says otherwise
+func DefaultConfig() *Config {
+	return &Config{
+		MinTeams: engine.MinTeams,
+		MaxTeams: engine.MaxTeams,
+		Theme:    "classic",
+		Images:   ImagesAuto,
+		Rules:    RulesConfig{NegativeScores: true},
+	}
+}
+
+// userDataPath returns where the data file name is kept, in the directory of
+// the user config file so tuipardy's files stay together. Without a home
+// directory it falls back to the working directory.
+func userDataPath(name string) string {
+	if p := UserConfigPath(); p != "" {
+		return filepath.Join(filepath.Dir(p), name)
+	}
+	return name
+}
+
+// UserConfigPath returns $XDG_CONFIG_HOME/tuipardy/config.toml, falling back
+// to ~/.config when XDG_CONFIG_HOME is unset
+func UserConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "tuipardy", "config.toml")
+}
+
+// BoardConfigPath returns the per-board config file for a board, e.g.
+// questions/board.toml for questions/board.csv
+func BoardConfigPath(boardPath string) string {
+	return strings.TrimSuffix(boardPath, filepath.Ext(boardPath)) + ".toml"
+}
+
+// LoadConfig layers the given config files over the defaults. Missing files
+// are skipped; later files override keys set by earlier ones. The result
+// isn't checked, as flags may still override it: call validate once they
+// have been applied.
+func LoadConfig(paths ...string) (*Config, error) {
+	cfg := DefaultConfig()
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		md, err := toml.DecodeFile(path, cfg)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("config %s: %w", path, err)
+		}
+		if len(md.Undecoded()) > 0 {
+			return nil, fmt.Errorf("config %s: unknown key %q", path, md.Undecoded()[0].String())
+		}
+		if md.IsDefined("theme") {
+			cfg.themeSet = true
+			// theme files are found next to the config file naming them
+			if _, builtin := builtinThemes[cfg.Theme]; !builtin && cfg.Theme != "" && !filepath.IsAbs(cfg.Theme) {
+				cfg.Theme = filepath.Join(filepath.Dir(path), cfg.Theme)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// EngineRules returns the engine rules the config describes
+func (c *Config) EngineRules() engine.Rules {
+	return engine.Rules{
+		MinTeams:       c.MinTeams,
+		MaxTeams:       c.MaxTeams,
+		NegativeScores: c.Rules.NegativeScores,
+		ReopenPicked:   c.Rules.ReopenPicked,
+	}
+}
+
+func (c *Config) validate() error {
+	if c.MinTeams < 1 || c.MaxTeams < c.MinTeams {
+		return fmt.Errorf("bad team limits %d-%d", c.MinTeams, c.MaxTeams)
+	}
+	if n := len(c.Teams); n > 0 && (n < c.MinTeams || n > c.MaxTeams) {
+		return fmt.Errorf("%d default teams, expected %d-%d", n, c.MinTeams, c.MaxTeams)
+	}
+	switch c.Images {
+	case ImagesAuto, ImagesKitty, ImagesNone:
+	default:
+		return fmt.Errorf("bad images setting %q, expected %s, %s or %s", c.Images, ImagesAuto, ImagesKitty, ImagesNone)
+	}
+	if c.Timers.Clue.Duration < 0 {
+		return fmt.Errorf("negative clue timer %s", c.Timers.Clue)
+	}
+	if _, err := NewKeymap(c.Keys); err != nil {
+		return fmt.Errorf("keys: %w", err)
+	}
+	return nil
+}
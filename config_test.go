@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigFlagsFix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tuipardy.toml")
+	if err := os.WriteFile(path, []byte("max_teams = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("loading a config a flag could still fix: %v", err)
+	}
+	if err := cfg.validate(); err == nil {
+		t.Errorf("max_teams below min_teams passed validation")
+	}
+	cfg.MaxTeams = 4 // as --max-teams 4 would
+	if err := cfg.validate(); err != nil {
+		t.Errorf("after the override: %v", err)
+	}
+}
+
+func TestLoadConfigTheme(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name      string
+		config    string
+		wantTheme string
+		wantSet   bool
+	}{
+		{"unset", "images = \"none\"\n", "classic", false},
+		{"default named", "theme = \"classic\"\n", "classic", true},
+		{"built-in", "theme = \"light\"\n", "light", true},
+		{"relative file", "theme = \"themes/mine.toml\"\n", filepath.Join(dir, "themes/mine.toml"), true},
+		{"absolute file", "theme = \"/etc/mine.toml\"\n", "/etc/mine.toml", true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, "board.toml")
+		if err := os.WriteFile(path, []byte(tt.config), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := LoadConfig(filepath.Join(dir, "missing.toml"), path)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if cfg.Theme != tt.wantTheme || cfg.themeSet != tt.wantSet {
+			t.Errorf("%s: theme %q set %v, want %q set %v", tt.name, cfg.Theme, cfg.themeSet, tt.wantTheme, tt.wantSet)
+		}
+	}
+}
+
+func TestUserDataPath(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	want := filepath.Join(dir, "tuipardy")
+	for _, path := range []string{UsedHistoryPath(), StudyHistoryPath(), SSHHostKeyPath()} {
+		if filepath.Dir(path) != want {
+			t.Errorf("%s isn't kept in %s", path, want)
+		}
+	}
+}
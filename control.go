@@ -0,0 +1,400 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/maristcomputersociety/tuipardy/engine"
+	"github.com/maristcomputersociety/tuipardy/spectator"
+)
+
+// The control socket takes newline-delimited JSON requests from scripts and
+// macro pads, one object per line, e.g.
+//
+//	{"id": 1, "cmd": "open", "category": "Algorithms", "value": 300}
+//	{"id": 2, "cmd": "judge", "team": 2, "correct": true}
+//
+// and answers each with {"id": ..., "ok": true} or {"id": ..., "ok": false,
+// "error": "..."}. After {"cmd": "subscribe"} the connection also gets a line
+// like {"event": "clue_opened", ...} for every change to the game. Requests
+// are carried to the host's loop as screen events, so they apply between key
+// presses exactly like them.
+
+// controlQueue is how many lines a connection may fall behind by before it
+// is dropped
+const controlQueue = 256
+
+// controlRequest is one request line. Fields other than id and cmd only
+// matter to the commands that use them.
+type controlRequest struct {
+	ID       json.RawMessage `json:"id,omitempty"`
+	Cmd      string          `json:"cmd"`
+	Dir      string          `json:"dir,omitempty"`      // move: left, right, up or down
+	Category string          `json:"category,omitempty"` // open: the cell to open, instead of the cursor
+	Value    int             `json:"value,omitempty"`    // open
+	Team     teamRef         `json:"team,omitempty"`     // judge, buzz, control
+	Correct  bool            `json:"correct,omitempty"`  // judge
+	Score    string          `json:"score,omitempty"`    // adjust: a score command, e.g. "1+200,3-200"
+}
+
+type controlReply struct {
+	ID    json.RawMessage  `json:"id,omitempty"`
+	OK    bool             `json:"ok"`
+	Error string           `json:"error,omitempty"`
+	State *spectator.State `json:"state,omitempty"`
+}
+
+// teamRef names a team by number, counting from 1, or by name prefix as in
+// score commands. It is written as a JSON number or string.
+type teamRef string
+
+func (t *teamRef) UnmarshalJSON(data []byte) error {
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err == nil {
+		*t = teamRef(n)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return errors.New("team must be a number or a name")
+	}
+	*t = teamRef(s)
+	return nil
+}
+
+// score returns a score command for the team, e.g. "2+" or "red-"
+func (t teamRef) score(op byte) (string, error) {
+	s := strings.TrimSpace(string(t))
+	if s == "" {
+		return "", errors.New("missing team")
+	}
+	return s + string(op), nil
+}
+
+// index resolves the team to its position in the team list
+func (t teamRef) index(e *engine.Engine) (int, error) {
+	text, err := t.score('+')
+	if err != nil {
+		return 0, err
+	}
+	changes, err := e.ParseScore(text + "0")
+	if err != nil {
+		return 0, err
+	}
+	return changes[0].Team, nil
+}
+
+// controlConn is a connection to the control socket. out is only sent to
+// and closed from the host's loop, or from the connection's own goroutine
+// once that loop has stopped.
+type controlConn struct {
+	out        chan []byte
+	dropped    chan struct{} // closed with out
+	subscribed bool
+	closed     bool
+}
+
+// controlEvent carries a request line to the host's loop
+type controlEvent struct {
+	tcell.EventTime
+	c   *controlConn
+	req *controlRequest // nil when the connection has closed
+	err error           // the line wasn't valid JSON
+}
+
+func newControlEvent(c *controlConn, req *controlRequest, err error) *controlEvent {
+	e := &controlEvent{c: c, req: req, err: err}
+	e.SetEventNow()
+	return e
+}
+
+// controlServer hands requests from the control socket to a game
+type controlServer struct {
+	g     *Game
+	conns map[*controlConn]bool // only touched from the host's loop
+}
+
+// startControl listens for requests on a Unix socket at path, replacing a
+// stale socket left by an earlier run. The socket is only accessible to the
+// current user. Closing the returned listener removes it.
+func startControl(g *Game, path string) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if c, err := net.Dial("unix", path); err == nil {
+			c.Close()
+			return nil, fmt.Errorf("%s is in use by another game", path)
+		}
+		os.Remove(path)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	srv := &controlServer{g: g, conns: map[*controlConn]bool{}}
+	g.control = srv
+	g.e.Subscribe(srv.broadcast)
+	go srv.serve(ln)
+	return ln, nil
+}
+
+func (srv *controlServer) serve(ln net.Listener) {
+	// requests need the host's screen to post them to
+	<-srv.g.running
+	for {
+		nc, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go srv.handleConn(nc)
+	}
+}
+
+func (srv *controlServer) handleConn(nc net.Conn) {
+	g := srv.g
+	c := &controlConn{out: make(chan []byte, controlQueue), dropped: make(chan struct{})}
+	go func() {
+		for line := range c.out {
+			if _, err := nc.Write(line); err != nil {
+				break
+			}
+		}
+		nc.Close()
+	}()
+
+	// hang up once the game is over, as no one answers requests any more.
+	// The game only drops connections that have sent it something, so one
+	// that never did, or whose last request was still queued, is closed here.
+	go func() {
+		select {
+		case <-g.stopped:
+			if !c.closed {
+				c.closed = true
+				close(c.out)
+			}
+			nc.Close()
+		case <-c.dropped:
+		}
+	}()
+
+	sc := bufio.NewScanner(nc)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		req := &controlRequest{}
+		err := json.Unmarshal([]byte(line), req)
+		if !g.postEvent(newControlEvent(c, req, err)) {
+			return
+		}
+	}
+	g.postEvent(newControlEvent(c, nil, nil))
+}
+
+// send queues a line for a connection, dropping connections that have
+// stopped reading
+func (srv *controlServer) send(c *controlConn, v any) {
+	if c.closed {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	select {
+	case c.out <- append(data, '\n'):
+	default:
+		srv.drop(c)
+	}
+}
+
+func (srv *controlServer) drop(c *controlConn) {
+	if !c.closed {
+		c.closed = true
+		close(c.out)
+		close(c.dropped)
+	}
+	delete(srv.conns, c)
+}
+
+// close disconnects every connection, when the game ends
+func (srv *controlServer) close() {
+	for c := range srv.conns {
+		srv.drop(c)
+	}
+}
+
+// handleControl answers a request from the control socket
+func (g *Game) handleControl(ev *controlEvent) {
+	srv := g.control
+	c := ev.c
+	if c.closed {
+		return
+	}
+	if ev.req == nil {
+		srv.drop(c)
+		return
+	}
+	srv.conns[c] = true
+	reply := controlReply{ID: ev.req.ID, OK: true}
+	err := ev.err
+	if err == nil {
+		reply.State, err = g.controlCommand(c, ev.req)
+	}
+	if err != nil {
+		reply.OK, reply.Error = false, err.Error()
+	}
+	srv.send(c, reply)
+}
+
+// controlCommand applies a request, the same way the matching keys would
+func (g *Game) controlCommand(c *controlConn, req *controlRequest) (*spectator.State, error) {
+	switch req.Cmd {
+	case "state":
+		st := spectator.Snapshot(g.e, plainClue)
+		return &st, nil
+	case "subscribe":
+		c.subscribed = true
+		return nil, nil
+	case "move":
+		moves := map[string]engine.Move{
+			"left":  {DCol: -1},
+			"right": {DCol: +1},
+			"up":    {DRow: -1},
+			"down":  {DRow: +1},
+		}
+		m, ok := moves[req.Dir]
+		if !ok {
+			return nil, fmt.Errorf("bad direction %q, expected left, right, up or down", req.Dir)
+		}
+		return nil, g.e.Do(m)
+	case "open":
+		if req.Category != "" {
+			col, row, err := g.findCell(req.Category, req.Value)
+			if err != nil {
+				return nil, err
+			}
+			// a taken cell fails before the cursor moves to it
+			if g.e.Board().At(col, row).Picked {
+				return nil, engine.ErrTaken
+			}
+			if err := g.e.Do(engine.Select{Col: col, Row: row}); err != nil {
+				return nil, err
+			}
+		}
+		return nil, g.e.Do(engine.Open{})
+	case "reveal":
+		return nil, g.e.Do(engine.Reveal{})
+	case "back":
+		return nil, g.e.Do(engine.Back{})
+	case "judge":
+		op := byte('-')
+		if req.Correct {
+			op = '+'
+		}
+		text, err := req.Team.score(op)
+		if err != nil {
+			return nil, err
+		}
+		return nil, g.applyScoreCommands(text)
+	case "adjust":
+		return nil, g.applyScoreCommands(req.Score)
+	case "undo":
+		return nil, g.e.Do(engine.Undo{})
+	case "buzz":
+		i, err := req.Team.index(g.e)
+		if err != nil {
+			return nil, err
+		}
+		return nil, g.e.Do(engine.Buzz{Team: i})
+	case "control":
+		i, err := req.Team.index(g.e)
+		if err != nil {
+			return nil, err
+		}
+		return nil, g.e.Do(engine.SetControl{Team: i})
+	case "":
+		return nil, errors.New("missing cmd")
+	}
+	return nil, fmt.Errorf("unknown cmd %q", req.Cmd)
+}
+
+// findCell finds the board cell with a category name, ignoring case, and value
+func (g *Game) findCell(category string, value int) (col, row int, err error) {
+	for c, cat := range g.e.Board().Categories {
+		if !strings.EqualFold(cat.Name, strings.TrimSpace(category)) {
+			continue
+		}
+		for r, q := range cat.Questions {
+			if q.Value == value {
+				return c, r, nil
+			}
+		}
+		return 0, 0, fmt.Errorf("%w: no %d in %s", engine.ErrNoSuchCell, value, cat.Name)
+	}
+	return 0, 0, fmt.Errorf("%w: no category %q", engine.ErrNoSuchCell, category)
+}
+
+// broadcast sends an engine event to every subscribed connection
+func (srv *controlServer) broadcast(ev engine.Event) {
+	msg := controlEventJSON(ev)
+	if msg == nil {
+		return
+	}
+	for c := range srv.conns {
+		if c.subscribed {
+			srv.send(c, msg)
+		}
+	}
+}
+
+// controlEventJSON describes an engine event for the control socket
+func controlEventJSON(ev engine.Event) map[string]any {
+	teamName := func(t *engine.Team) any {
+		if t == nil {
+			return nil
+		}
+		return t.Name
+	}
+	switch ev := ev.(type) {
+	case engine.PhaseChanged:
+		return map[string]any{"event": "phase_changed", "phase": ev.Phase.String()}
+	case engine.CursorMoved:
+		return map[string]any{"event": "cursor_moved", "col": ev.Col, "row": ev.Row,
+			"category": ev.Question.Category, "value": ev.Question.Value, "picked": ev.Question.Picked}
+	case engine.ClueOpened:
+		return map[string]any{"event": "clue_opened", "category": ev.Question.Category,
+			"value": ev.Question.Value, "question": plainClue(ev.Question.Q)}
+	case engine.AnswerToggled:
+		msg := map[string]any{"event": "answer_toggled", "shown": ev.Shown}
+		if ev.Shown {
+			msg["answer"] = plainClue(ev.Question.A)
+		}
+		return msg
+	case engine.ScoresChanged:
+		results := make([]map[string]any, len(ev.Results))
+		for i, r := range ev.Results {
+			results[i] = map[string]any{"team": r.Team.Name, "op": string(r.Change.Op),
+				"value": r.Change.Value, "score": r.Team.Score}
+		}
+		return map[string]any{"event": "scores_changed", "results": results}
+	case engine.ControlChanged:
+		return map[string]any{"event": "control_changed", "team": teamName(ev.Team)}
+	case engine.BuzzerChanged:
+		return map[string]any{"event": "buzzer_changed", "team": teamName(ev.Team)}
+	case engine.TeamsChanged:
+		return map[string]any{"event": "teams_changed", "what": ev.What}
+	case engine.Undone:
+		return map[string]any{"event": "undone", "what": ev.What}
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startTestControl runs a test game with a control socket
+func startTestControl(t *testing.T) *testGame {
+	t.Helper()
+	tg := newTestGame(t)
+	tg.addr = filepath.Join(t.TempDir(), "control.sock")
+	ln, err := startControl(tg.g, tg.addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	tg.run(t)
+	return tg
+}
+
+// controlClient is a connection to a control socket
+type controlClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dialControl(t *testing.T, path string) *controlClient {
+	t.Helper()
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &controlClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+// send writes a request line as is
+func (c *controlClient) send(line string) {
+	c.t.Helper()
+	if _, err := fmt.Fprintln(c.conn, line); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// read returns the next line from the game, decoded
+func (c *controlClient) read() map[string]any {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		c.t.Fatalf("reading a reply: %v", err)
+	}
+	var msg map[string]any
+	if err := json.Unmarshal([]byte(line), &msg); err != nil {
+		c.t.Fatalf("reply %q: %v", line, err)
+	}
+	return msg
+}
+
+// do sends a request and returns its reply, skipping event lines
+func (c *controlClient) do(line string) map[string]any {
+	c.t.Helper()
+	c.send(line)
+	for {
+		if msg := c.read(); msg["event"] == nil {
+			return msg
+		}
+	}
+}
+
+func TestControlReplies(t *testing.T) {
+	tg := startTestControl(t)
+	c := dialControl(t, tg.addr)
+	tests := []struct {
+		req     string
+		wantID  any // as decoded from JSON
+		wantErr string
+	}{
+		{`{"id": 1, "cmd": "move", "dir": "right"}`, 1.0, ""},
+		{`{"id": "a", "cmd": "move", "dir": "sideways"}`, "a", `bad direction "sideways"`},
+		{`{"id": [1, 2], "cmd": "state"}`, []any{1.0, 2.0}, ""},
+		{`{"cmd": "reveal"}`, nil, "not possible right now"},
+		{`{"id": 2, "cmd": "fly"}`, 2.0, `unknown cmd "fly"`},
+		{`{"id": 3}`, 3.0, "missing cmd"},
+		{`{"id": 4, "cmd": "buzz", "team": {}}`, 4.0, "team must be a number or a name"},
+		{`{"id": 5, "cmd": "open"`, nil, "unexpected end of JSON input"},
+		{`not json`, nil, "invalid character"},
+		{`{"id": 6, "cmd": "open", "category": "nope", "value": 100}`, 6.0, `no category "nope"`},
+		{`{"id": 7, "cmd": "open"}`, 7.0, ""},
+		{`{"id": 8, "cmd": "buzz", "team": "bl"}`, 8.0, ""},
+		{`{"id": 9, "cmd": "buzz", "team": 1}`, 9.0, "already buzzed in: Blue"},
+		{`{"id": 10, "cmd": "judge", "team": 2, "correct": true}`, 10.0, ""},
+	}
+	for _, tt := range tests {
+		reply := c.do(tt.req)
+		if fmt.Sprint(reply["id"]) != fmt.Sprint(tt.wantID) {
+			t.Errorf("%s: id %v, want %v", tt.req, reply["id"], tt.wantID)
+		}
+		errText, _ := reply["error"].(string)
+		if ok := reply["ok"] == true; ok != (tt.wantErr == "") || !strings.Contains(errText, tt.wantErr) {
+			t.Errorf("%s: reply %v, want error %q", tt.req, reply, tt.wantErr)
+		}
+	}
+
+	st := c.do(`{"cmd": "state"}`)["state"].(map[string]any)
+	teams := st["teams"].([]any)
+	if blue := teams[1].(map[string]any); blue["score"] != 100.0 {
+		t.Errorf("Blue's score %v, want 100", blue["score"])
+	}
+}
+
+func TestControlOpenTaken(t *testing.T) {
+	tg := startTestControl(t)
+	c := dialControl(t, tg.addr)
+	st := c.do(`{"cmd": "state"}`)["state"].(map[string]any)
+	cat := st["categories"].([]any)[2].(map[string]any)["name"].(string)
+	open := fmt.Sprintf(`{"cmd": "open", "category": %q, "value": 200}`, strings.ToLower(cat))
+
+	if r := c.do(open); r["ok"] != true {
+		t.Fatalf("open: %v", r)
+	}
+	c.do(`{"cmd": "back"}`)
+	c.do(`{"cmd": "move", "dir": "left"}`)
+	events := dialControl(t, tg.addr)
+	events.do(`{"cmd": "subscribe"}`)
+
+	if r := c.do(open); r["ok"] != false || r["error"] != "already taken" {
+		t.Errorf("opening a taken cell: %v", r)
+	}
+	// nothing happened, so the next line the subscriber gets is this state
+	c.do(`{"cmd": "state"}`)
+	c.do(`{"cmd": "move", "dir": "right"}`)
+	if ev := events.read(); ev["event"] != "cursor_moved" || ev["col"] != 2.0 {
+		t.Errorf("first event after opening a taken cell: %v, want the cursor moving to column 2", ev)
+	}
+}
+
+func TestControlSubscribe(t *testing.T) {
+	tg := startTestControl(t)
+	c := dialControl(t, tg.addr)
+	if r := c.do(`{"id": 1, "cmd": "subscribe"}`); r["ok"] != true {
+		t.Fatalf("subscribe: %v", r)
+	}
+	other := dialControl(t, tg.addr)
+	other.do(`{"cmd": "open"}`)
+	other.do(`{"cmd": "adjust", "score": "1+100,2-100"}`)
+
+	want := []string{"clue_opened", "phase_changed", "scores_changed"}
+	var got []string
+	for range want {
+		ev := c.read()
+		got = append(got, fmt.Sprint(ev["event"]))
+		if ev["event"] == "scores_changed" {
+			results := ev["results"].([]any)
+			if r := results[1].(map[string]any); r["team"] != "Blue" || r["score"] != -100.0 {
+				t.Errorf("results %v", results)
+			}
+		}
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("events %v, want %v", got, want)
+	}
+}
+
+func TestControlAfterGameEnds(t *testing.T) {
+	tg := startTestControl(t)
+	c := dialControl(t, tg.addr)
+	c.do(`{"cmd": "state"}`)
+	idle := dialControl(t, tg.addr) // never sends a request
+	tg.stop(t)
+
+	// nothing answers now, so requests mustn't pile up waiting for the game
+	for range 500 {
+		if _, err := fmt.Fprintln(c.conn, `{"cmd": "state"}`); err != nil {
+			break
+		}
+	}
+	for _, cl := range []*controlClient{c, idle} {
+		cl.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		for {
+			_, err := cl.r.ReadString('\n')
+			if err == nil {
+				continue
+			}
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				t.Errorf("connection still open after the game ended")
+			}
+			break
+		}
+	}
+
+	// and nothing is left waiting to write to them
+	buf := make([]byte, 1<<20)
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		stacks := string(buf[:runtime.Stack(buf, true)])
+		if !strings.Contains(stacks, "(*controlServer).handleConn") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("connection goroutines still running:\n%s", stacks)
+		}
+	}
+}
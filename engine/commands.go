@@ -0,0 +1,388 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/maristcomputersociety/tuipardy/board"
+)
+
+// Command is something a player or host does, applied with Engine.Do
+type Command interface {
+	apply(e *Engine) error
+}
+
+var (
+	ErrWrongPhase    = errors.New("not possible right now")
+	ErrTaken         = errors.New("already taken")
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNoSuchTeam    = errors.New("no such team")
+	ErrNoSuchCell    = errors.New("no such cell")
+	ErrTooManyTeams  = errors.New("too many teams")
+	ErrTooFewTeams   = errors.New("too few teams")
+	ErrEmptyName     = errors.New("team name is empty")
+	ErrBuzzedIn      = errors.New("someone already buzzed in")
+)
+
+func (e *Engine) checkPhase(phases ...Phase) error {
+	for _, p := range phases {
+		if e.phase == p {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w in the %s phase", ErrWrongPhase, e.phase)
+}
+
+func (e *Engine) checkTeam(i int) error {
+	if i < 0 || i >= len(e.teams) {
+		return fmt.Errorf("%w: %d", ErrNoSuchTeam, i+1)
+	}
+	return nil
+}
+
+func (e *Engine) setPhase(p Phase) {
+	e.phase = p
+	e.emit(PhaseChanged{Phase: p})
+}
+
+// StartGame creates the teams and moves to the board. Empty names become
+// "Team N".
+type StartGame struct {
+	Teams []string
+}
+
+func (c StartGame) apply(e *Engine) error {
+	if err := e.checkPhase(PhaseSetup); err != nil {
+		return err
+	}
+	if n := len(c.Teams); n < e.rules.MinTeams || n > e.rules.MaxTeams {
+		return fmt.Errorf("%d teams, expected %d-%d", n, e.rules.MinTeams, e.rules.MaxTeams)
+	}
+	for i, name := range c.Teams {
+		if name = strings.TrimSpace(name); name == "" {
+			name = fmt.Sprintf("Team %d", i+1)
+		}
+		e.teams = append(e.teams, &Team{Name: name})
+	}
+	e.emit(TeamsChanged{What: fmt.Sprintf("%d teams", len(e.teams))})
+	e.setPhase(PhaseBoard)
+	return nil
+}
+
+// Move moves the cursor by whole cells, wrapping around the board edges
+type Move struct {
+	DCol, DRow int
+}
+
+func (c Move) apply(e *Engine) error {
+	if err := e.checkPhase(PhaseBoard); err != nil {
+		return err
+	}
+	cols, rows := len(e.board.Categories), board.QuestionsPerCategory
+	col := ((e.col+c.DCol)%cols + cols) % cols
+	row := ((e.row+c.DRow)%rows + rows) % rows
+	return Select{Col: col, Row: row}.apply(e)
+}
+
+// Select puts the cursor on a cell
+type Select struct {
+	Col, Row int
+}
+
+func (c Select) apply(e *Engine) error {
+	if err := e.checkPhase(PhaseBoard); err != nil {
+		return err
+	}
+	if c.Col < 0 || c.Col >= len(e.board.Categories) || c.Row < 0 || c.Row >= board.QuestionsPerCategory {
+		return fmt.Errorf("%w: %d,%d", ErrNoSuchCell, c.Col, c.Row)
+	}
+	e.col, e.row = c.Col, c.Row
+	e.emit(CursorMoved{Col: c.Col, Row: c.Row, Question: e.board.At(c.Col, c.Row)})
+	return nil
+}
+
+// Open opens the clue under the cursor
+type Open struct{}
+
+func (Open) apply(e *Engine) error {
+	if err := e.checkPhase(PhaseBoard); err != nil {
+		return err
+	}
+	q := e.board.At(e.col, e.row)
+	if q.Picked && !e.rules.ReopenPicked {
+		return ErrTaken
+	}
+	q.Picked = true
+	e.current = q
+	e.showAnswer = false
+	e.buzzed = nil
+	e.lastValue = q.Value
+	e.emit(ClueOpened{Question: q})
+	e.setPhase(PhaseQuestion)
+	return nil
+}
+
+// Reveal switches the open clue between its question and its answer
+type Reveal struct{}
+
+func (Reveal) apply(e *Engine) error {
+	if err := e.checkPhase(PhaseQuestion); err != nil {
+		return err
+	}
+	e.showAnswer = !e.showAnswer
+	e.emit(AnswerToggled{Question: e.current, Shown: e.showAnswer})
+	return nil
+}
+
+// Back closes the open clue and returns to the board
+type Back struct{}
+
+func (Back) apply(e *Engine) error {
+	if err := e.checkPhase(PhaseQuestion); err != nil {
+		return err
+	}
+	e.current = nil
+	e.showAnswer = false
+	e.buzzed = nil
+	e.setPhase(PhaseBoard)
+	return nil
+}
+
+// Buzz buzzes a team in on the open clue. Only the first team gets in; the
+// buzzers open again when that team loses points with -.
+type Buzz struct {
+	Team int
+}
+
+func (c Buzz) apply(e *Engine) error {
+	if err := e.checkPhase(PhaseQuestion); err != nil {
+		return err
+	}
+	if err := e.checkTeam(c.Team); err != nil {
+		return err
+	}
+	if e.buzzed != nil {
+		return fmt.Errorf("%w: %s", ErrBuzzedIn, e.buzzed.Name)
+	}
+	e.buzzed = e.teams[c.Team]
+	e.emit(BuzzerChanged{Team: e.buzzed})
+	return nil
+}
+
+// Adjust applies a batch of score changes, as parsed by ParseScore. A team
+// given points with + has answered correctly and gets control of the board.
+// Taking points from the team that buzzed in opens the buzzers again.
+type Adjust struct {
+	Changes []ScoreChange
+	Label   string // how the change is described in the undo history
+}
+
+func (c Adjust) apply(e *Engine) error {
+	if err := e.checkPhase(PhaseBoard, PhaseQuestion); err != nil {
+		return err
+	}
+	for _, ch := range c.Changes {
+		if err := e.checkTeam(ch.Team); err != nil {
+			return err
+		}
+		if ch.Op != '+' && ch.Op != '-' && ch.Op != '=' {
+			return fmt.Errorf("bad score operator %q", ch.Op)
+		}
+	}
+	label := c.Label
+	if label == "" {
+		label = "score change"
+	}
+	e.pushHistory(label)
+
+	before, buzzed := e.control, e.buzzed
+	results := make([]ScoreResult, 0, len(c.Changes))
+	for _, ch := range c.Changes {
+		t := e.teams[ch.Team]
+		switch ch.Op {
+		case '+':
+			t.Score += ch.Value
+			e.control = t
+		case '-':
+			t.Score -= ch.Value
+			if t == e.buzzed {
+				e.buzzed = nil
+			}
+		case '=':
+			t.Score = ch.Value
+		}
+		if !e.rules.NegativeScores && t.Score < 0 {
+			t.Score = 0
+		}
+		results = append(results, ScoreResult{Team: t, Change: ch})
+	}
+	e.emit(ScoresChanged{Results: results})
+	if e.control != before {
+		e.emit(ControlChanged{Team: e.control})
+	}
+	if e.buzzed != buzzed {
+		e.emit(BuzzerChanged{})
+	}
+	return nil
+}
+
+// SetControl gives the next pick to a team, overriding the last correct
+// response
+type SetControl struct {
+	Team int
+}
+
+func (c SetControl) apply(e *Engine) error {
+	if err := e.checkPhase(PhaseBoard, PhaseQuestion); err != nil {
+		return err
+	}
+	if err := e.checkTeam(c.Team); err != nil {
+		return err
+	}
+	e.control = e.teams[c.Team]
+	e.emit(ControlChanged{Team: e.control})
+	return nil
+}
+
+// AddTeam adds a team with no points at the end of the list
+type AddTeam struct {
+	Name string
+}
+
+func (c AddTeam) apply(e *Engine) error {
+	if err := e.checkPhase(PhaseBoard, PhaseQuestion); err != nil {
+		return err
+	}
+	name := strings.TrimSpace(c.Name)
+	if name == "" {
+		return ErrEmptyName
+	}
+	if len(e.teams) >= e.rules.MaxTeams {
+		return fmt.Errorf("%w, the most is %d", ErrTooManyTeams, e.rules.MaxTeams)
+	}
+	e.pushHistory("adding " + name)
+	e.teams = append(e.teams, &Team{Name: name})
+	e.emit(TeamsChanged{What: fmt.Sprintf("added team %d: %s", len(e.teams), name)})
+	return nil
+}
+
+// RenameTeam changes a team's name
+type RenameTeam struct {
+	Team int
+	Name string
+}
+
+func (c RenameTeam) apply(e *Engine) error {
+	if err := e.checkPhase(PhaseBoard, PhaseQuestion); err != nil {
+		return err
+	}
+	if err := e.checkTeam(c.Team); err != nil {
+		return err
+	}
+	name := strings.TrimSpace(c.Name)
+	if name == "" {
+		return ErrEmptyName
+	}
+	t := e.teams[c.Team]
+	if name == t.Name {
+		return nil
+	}
+	old := t.Name
+	e.pushHistory("renaming " + old)
+	t.Name = name
+	e.emit(TeamsChanged{What: fmt.Sprintf("renamed %s to %s", old, name)})
+	return nil
+}
+
+// RemoveTeam drops a team and its score
+type RemoveTeam struct {
+	Team int
+}
+
+func (c RemoveTeam) apply(e *Engine) error {
+	if err := e.checkPhase(PhaseBoard, PhaseQuestion); err != nil {
+		return err
+	}
+	if err := e.checkTeam(c.Team); err != nil {
+		return err
+	}
+	if len(e.teams) <= e.rules.MinTeams {
+		return fmt.Errorf("%w, the fewest is %d", ErrTooFewTeams, e.rules.MinTeams)
+	}
+	t := e.teams[c.Team]
+	e.pushHistory("removing " + t.Name)
+	e.teams = append(e.teams[:c.Team:c.Team], e.teams[c.Team+1:]...)
+	e.emit(TeamsChanged{What: "removed " + t.Name})
+	if e.control == t {
+		e.control = nil
+		e.emit(ControlChanged{})
+	}
+	if e.buzzed == t {
+		e.buzzed = nil
+		e.emit(BuzzerChanged{})
+	}
+	return nil
+}
+
+// MergeTeams adds one team's score to another's and drops the first
+type MergeTeams struct {
+	From, Into int
+}
+
+func (c MergeTeams) apply(e *Engine) error {
+	if err := e.checkPhase(PhaseBoard, PhaseQuestion); err != nil {
+		return err
+	}
+	if err := e.checkTeam(c.From); err != nil {
+		return err
+	}
+	if err := e.checkTeam(c.Into); err != nil {
+		return err
+	}
+	if c.From == c.Into {
+		return fmt.Errorf("can't merge %s into itself", e.teams[c.From].Name)
+	}
+	if len(e.teams) <= e.rules.MinTeams {
+		return fmt.Errorf("%w, the fewest is %d", ErrTooFewTeams, e.rules.MinTeams)
+	}
+	src, dst := e.teams[c.From], e.teams[c.Into]
+	e.pushHistory(fmt.Sprintf("merging %s into %s", src.Name, dst.Name))
+	dst.Score += src.Score
+	e.teams = append(e.teams[:c.From:c.From], e.teams[c.From+1:]...)
+	e.emit(TeamsChanged{What: fmt.Sprintf("merged %s into %s (now %d)", src.Name, dst.Name, dst.Score)})
+	if e.control == src {
+		e.control = dst
+		e.emit(ControlChanged{Team: dst})
+	}
+	if e.buzzed == src {
+		e.buzzed = dst
+		e.emit(BuzzerChanged{Team: dst})
+	}
+	return nil
+}
+
+// MoveTeam moves a team up (By < 0) or down (By > 0) the list
+type MoveTeam struct {
+	Team int
+	By   int
+}
+
+func (c MoveTeam) apply(e *Engine) error {
+	if err := e.checkPhase(PhaseBoard, PhaseQuestion); err != nil {
+		return err
+	}
+	if err := e.checkTeam(c.Team); err != nil {
+		return err
+	}
+	to := c.Team + c.By
+	if err := e.checkTeam(to); err != nil {
+		return err
+	}
+	t := e.teams[c.Team]
+	e.pushHistory("moving " + t.Name)
+	teams := append(e.teams[:c.Team:c.Team], e.teams[c.Team+1:]...)
+	e.teams = append(teams[:to:to], append([]*Team{t}, teams[to:]...)...)
+	e.emit(TeamsChanged{What: fmt.Sprintf("%s is now team %d", t.Name, to+1)})
+	return nil
+}
@@ -0,0 +1,144 @@
+// Package engine holds the rules of a tuipardy game played on a board from
+// package board: the teams, picking clues, scoring and undo. It knows nothing
+// about terminals. Frontends drive it with commands through Do and follow
+// along with the events it sends to subscribers. Like package board, its
+// exported API follows semantic versioning.
+//
+// A game with two teams where the first one takes the first clue:
+//
+//	b, err := board.Load("questions/board.csv")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	e, err := engine.New(b, engine.DefaultRules())
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	e.Subscribe(func(ev engine.Event) {
+//		if ev, ok := ev.(engine.ClueOpened); ok {
+//			fmt.Println(ev.Question.Q)
+//		}
+//	})
+//	e.Do(engine.StartGame{Teams: []string{"Red", "Blue"}})
+//	e.Do(engine.Open{})
+//	e.Do(engine.Adjust{Changes: []engine.ScoreChange{{Team: 0, Op: '+', Value: e.LastValue()}}})
+//	e.Do(engine.Back{})
+package engine
+
+import (
+	"fmt"
+
+	"github.com/maristcomputersociety/tuipardy/board"
+)
+
+// Phase is the stage the game is in
+type Phase int
+
+const (
+	PhaseSetup    Phase = iota // waiting for StartGame
+	PhaseBoard                 // picking a clue
+	PhaseQuestion              // a clue is open
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseSetup:
+		return "setup"
+	case PhaseBoard:
+		return "board"
+	case PhaseQuestion:
+		return "question"
+	}
+	return "unknown"
+}
+
+// Rules are the settings that change how the game plays
+type Rules struct {
+	MinTeams       int
+	MaxTeams       int
+	NegativeScores bool // let scores drop below zero
+	ReopenPicked   bool // allow opening taken clues again
+}
+
+// DefaultRules returns the rules used when nothing says otherwise
+func DefaultRules() Rules {
+	return Rules{MinTeams: MinTeams, MaxTeams: MaxTeams, NegativeScores: true}
+}
+
+// Engine is the state of one game
+type Engine struct {
+	rules      Rules
+	board      *board.Board
+	phase      Phase
+	teams      []*Team
+	col, row   int // cursor
+	current    *board.Question
+	showAnswer bool
+	lastValue  int   // value of the current or last opened clue
+	control    *Team // team picking the next clue, nil until someone answers
+	buzzed     *Team // first team to buzz in on the open clue
+	history    []snapshot
+	subs       []func(Event)
+}
+
+// New starts a game on b in the setup phase. The board must pass
+// b.Validate; loaded boards already have.
+func New(b *board.Board, rules Rules) (*Engine, error) {
+	if err := b.Validate(); err != nil {
+		return nil, fmt.Errorf("board: %w", err)
+	}
+	return &Engine{rules: rules, board: b}, nil
+}
+
+// Subscribe registers fn to be called with every event, in order, from the
+// goroutine calling Do
+func (e *Engine) Subscribe(fn func(Event)) {
+	e.subs = append(e.subs, fn)
+}
+
+func (e *Engine) emit(ev Event) {
+	for _, fn := range e.subs {
+		fn(ev)
+	}
+}
+
+// Do applies a command, emitting events for what changed. Nothing changes
+// when it returns an error.
+func (e *Engine) Do(cmd Command) error {
+	return cmd.apply(e)
+}
+
+func (e *Engine) Rules() Rules        { return e.rules }
+func (e *Engine) Board() *board.Board { return e.board }
+func (e *Engine) Phase() Phase        { return e.phase }
+func (e *Engine) LastValue() int      { return e.lastValue }
+func (e *Engine) CanUndo() bool       { return len(e.history) > 0 }
+
+// Teams returns the teams in order. The slice and teams must not be modified.
+func (e *Engine) Teams() []*Team { return e.teams }
+
+// Cursor returns the board cell that Open would open
+func (e *Engine) Cursor() (col, row int) { return e.col, e.row }
+
+// Current returns the open clue, or nil on the board
+func (e *Engine) Current() *board.Question { return e.current }
+
+// AnswerShown reports whether the open clue shows its answer
+func (e *Engine) AnswerShown() bool { return e.showAnswer }
+
+// Control returns the team picking the next clue, or nil if none has yet
+func (e *Engine) Control() *Team { return e.control }
+
+// Buzzed returns the team that buzzed in first on the open clue, or nil
+// while the buzzers are open
+func (e *Engine) Buzzed() *Team { return e.buzzed }
+
+// TeamIndex returns the position of t in the team list, or -1
+func (e *Engine) TeamIndex(t *Team) int {
+	for i := range e.teams {
+		if e.teams[i] == t {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,481 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/maristcomputersociety/tuipardy/board"
+)
+
+// testBoard returns a full board; clue (col, row) is worth (row+1)*100
+func testBoard() *board.Board {
+	b := &board.Board{}
+	for c := range board.ExpectedCategories {
+		cat := &board.Category{Name: fmt.Sprintf("Cat%d", c)}
+		for r := range board.QuestionsPerCategory {
+			cat.Questions = append(cat.Questions, &board.Question{
+				Category: cat.Name, Value: (r + 1) * 100, Q: fmt.Sprintf("q%d%d", c, r), A: "a",
+			})
+		}
+		b.Categories = append(b.Categories, cat)
+	}
+	return b
+}
+
+// recorder collects the events an engine sends, described as text
+type recorder struct{ events []string }
+
+func (r *recorder) record(ev Event) { r.events = append(r.events, describe(ev)) }
+
+func (r *recorder) take() []string {
+	events := r.events
+	r.events = nil
+	return events
+}
+
+func teamName(t *Team) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Name
+}
+
+func describe(ev Event) string {
+	switch ev := ev.(type) {
+	case PhaseChanged:
+		return "phase " + ev.Phase.String()
+	case CursorMoved:
+		return fmt.Sprintf("cursor %d,%d", ev.Col, ev.Row)
+	case ClueOpened:
+		return "opened " + ev.Question.Q
+	case AnswerToggled:
+		return fmt.Sprintf("answer %v", ev.Shown)
+	case ScoresChanged:
+		parts := make([]string, len(ev.Results))
+		for i, r := range ev.Results {
+			parts[i] = fmt.Sprintf("%s=%d", r.Team.Name, r.Team.Score)
+		}
+		return "scores " + strings.Join(parts, " ")
+	case ControlChanged:
+		return "control " + teamName(ev.Team)
+	case BuzzerChanged:
+		return "buzzer " + teamName(ev.Team)
+	case TeamsChanged:
+		return "teams " + ev.What
+	case Undone:
+		return "undone " + ev.What
+	}
+	return fmt.Sprintf("%T", ev)
+}
+
+// dump describes everything a command may change
+func dump(e *Engine) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "phase %s cursor %d,%d answer %v last %d control %s buzzed %s history %d\n",
+		e.phase, e.col, e.row, e.showAnswer, e.lastValue, teamName(e.control), teamName(e.buzzed), len(e.history))
+	if e.current != nil {
+		fmt.Fprintf(&b, "current %s\n", e.current.Q)
+	}
+	for _, t := range e.teams {
+		fmt.Fprintf(&b, "team %s %d\n", t.Name, t.Score)
+	}
+	for _, cat := range e.board.Categories {
+		for _, q := range cat.Questions {
+			if q.Picked {
+				fmt.Fprintf(&b, "picked %s\n", q.Q)
+			}
+		}
+	}
+	return b.String()
+}
+
+func newTestEngine(t *testing.T, rules Rules) (*Engine, *recorder) {
+	t.Helper()
+	e, err := New(testBoard(), rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &recorder{}
+	e.Subscribe(rec.record)
+	return e, rec
+}
+
+// mustDo applies commands that must succeed
+func mustDo(t *testing.T, e *Engine, cmds ...Command) {
+	t.Helper()
+	for _, cmd := range cmds {
+		if err := e.Do(cmd); err != nil {
+			t.Fatalf("%T%+v: %v", cmd, cmd, err)
+		}
+	}
+}
+
+// inPhase returns an engine with three teams in the given phase
+func inPhase(t *testing.T, p Phase) (*Engine, *recorder) {
+	t.Helper()
+	e, rec := newTestEngine(t, DefaultRules())
+	if p != PhaseSetup {
+		mustDo(t, e, StartGame{Teams: []string{"Red", "Blue", "Green"}})
+	}
+	if p == PhaseQuestion {
+		mustDo(t, e, Open{})
+	}
+	rec.take()
+	return e, rec
+}
+
+func TestNew(t *testing.T) {
+	empty := &board.Board{}
+	short := testBoard()
+	short.Categories[3].Questions = short.Categories[3].Questions[:2]
+	for _, b := range []*board.Board{empty, short} {
+		if e, err := New(b, DefaultRules()); err == nil || e != nil {
+			t.Errorf("New accepted an invalid board: %v", err)
+		}
+	}
+}
+
+func TestCommandPhases(t *testing.T) {
+	all := []Phase{PhaseSetup, PhaseBoard, PhaseQuestion}
+	both := []Phase{PhaseBoard, PhaseQuestion}
+	tests := []struct {
+		cmd     Command
+		allowed []Phase
+	}{
+		{StartGame{Teams: []string{"A", "B", "C"}}, []Phase{PhaseSetup}},
+		{Move{DCol: 1}, []Phase{PhaseBoard}},
+		{Select{Col: 1, Row: 1}, []Phase{PhaseBoard}},
+		{Open{}, []Phase{PhaseBoard}},
+		{Reveal{}, []Phase{PhaseQuestion}},
+		{Back{}, []Phase{PhaseQuestion}},
+		{Buzz{Team: 0}, []Phase{PhaseQuestion}},
+		{Adjust{Changes: []ScoreChange{{Team: 0, Op: '+', Value: 100}}}, both},
+		{SetControl{Team: 1}, both},
+		{AddTeam{Name: "Gold"}, both},
+		{RenameTeam{Team: 0, Name: "Crimson"}, both},
+		{RemoveTeam{Team: 2}, both},
+		{MergeTeams{From: 2, Into: 0}, both},
+		{MoveTeam{Team: 0, By: 1}, both},
+	}
+	for _, tt := range tests {
+		for _, p := range all {
+			e, rec := inPhase(t, p)
+			before := dump(e)
+			err := e.Do(tt.cmd)
+			if slices.Contains(tt.allowed, p) {
+				if err != nil {
+					t.Errorf("%T in %s: %v", tt.cmd, p, err)
+				}
+				continue
+			}
+			if !errors.Is(err, ErrWrongPhase) {
+				t.Errorf("%T in %s: got %v, want ErrWrongPhase", tt.cmd, p, err)
+			}
+			if after := dump(e); after != before {
+				t.Errorf("%T in %s failed but changed the game:\n%s\nto\n%s", tt.cmd, p, before, after)
+			}
+			if events := rec.take(); len(events) > 0 {
+				t.Errorf("%T in %s failed but sent %q", tt.cmd, p, events)
+			}
+		}
+	}
+}
+
+func TestFailedCommandsChangeNothing(t *testing.T) {
+	tests := []struct {
+		name    string
+		phase   Phase
+		setup   []Command
+		cmd     Command
+		wantErr error // nil to only check that it failed
+	}{
+		{"one team", PhaseSetup, nil, StartGame{Teams: []string{"A"}}, nil},
+		{"17 teams", PhaseSetup, nil, StartGame{Teams: make([]string, 17)}, nil},
+		{"select off the board", PhaseBoard, nil, Select{Col: 6}, ErrNoSuchCell},
+		{"select negative row", PhaseBoard, nil, Select{Row: -1}, ErrNoSuchCell},
+		{"open taken", PhaseBoard, []Command{Open{}, Back{}}, Open{}, ErrTaken},
+		{"buzz twice", PhaseQuestion, []Command{Buzz{Team: 0}}, Buzz{Team: 1}, ErrBuzzedIn},
+		{"buzz no team", PhaseQuestion, nil, Buzz{Team: 3}, ErrNoSuchTeam},
+		{"adjust no team", PhaseBoard, nil, Adjust{Changes: []ScoreChange{{Team: 0, Op: '+', Value: 1}, {Team: 5, Op: '+', Value: 1}}}, ErrNoSuchTeam},
+		{"adjust bad op", PhaseBoard, nil, Adjust{Changes: []ScoreChange{{Team: 0, Op: '*', Value: 2}}}, nil},
+		{"control no team", PhaseBoard, nil, SetControl{Team: -1}, ErrNoSuchTeam},
+		{"add empty name", PhaseBoard, nil, AddTeam{Name: "  "}, ErrEmptyName},
+		{"rename empty", PhaseBoard, nil, RenameTeam{Team: 0, Name: ""}, ErrEmptyName},
+		{"rename no team", PhaseBoard, nil, RenameTeam{Team: 3, Name: "X"}, ErrNoSuchTeam},
+		{"remove at minimum", PhaseBoard, []Command{RemoveTeam{Team: 0}}, RemoveTeam{Team: 0}, ErrTooFewTeams},
+		{"merge into itself", PhaseBoard, nil, MergeTeams{From: 1, Into: 1}, nil},
+		{"merge at minimum", PhaseBoard, []Command{RemoveTeam{Team: 0}}, MergeTeams{From: 0, Into: 1}, ErrTooFewTeams},
+		{"move off the end", PhaseBoard, nil, MoveTeam{Team: 2, By: 1}, ErrNoSuchTeam},
+		{"undo nothing", PhaseBoard, nil, Undo{}, ErrNothingToUndo},
+	}
+	for _, tt := range tests {
+		e, rec := inPhase(t, tt.phase)
+		mustDo(t, e, tt.setup...)
+		rec.take()
+		before := dump(e)
+		err := e.Do(tt.cmd)
+		if err == nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if after := dump(e); after != before {
+			t.Errorf("%s failed but changed the game:\n%s\nto\n%s", tt.name, before, after)
+		}
+		if events := rec.take(); len(events) > 0 {
+			t.Errorf("%s failed but sent %q", tt.name, events)
+		}
+	}
+}
+
+func TestTooManyTeams(t *testing.T) {
+	rules := DefaultRules()
+	rules.MaxTeams = 3
+	e, rec := newTestEngine(t, rules)
+	mustDo(t, e, StartGame{Teams: []string{"A", "B", "C"}})
+	rec.take()
+	before := dump(e)
+	if err := e.Do(AddTeam{Name: "D"}); !errors.Is(err, ErrTooManyTeams) {
+		t.Errorf("got %v, want ErrTooManyTeams", err)
+	}
+	if dump(e) != before || len(rec.take()) > 0 {
+		t.Errorf("failed AddTeam changed the game")
+	}
+}
+
+func TestMoveWraps(t *testing.T) {
+	tests := []struct {
+		dcol, drow int
+		col, row   int
+	}{
+		{1, 0, 1, 0},
+		{-1, 0, 5, 0},
+		{0, -1, 0, 2},
+		{0, 3, 0, 0},
+		{7, 4, 1, 1},
+	}
+	for _, tt := range tests {
+		e, _ := inPhase(t, PhaseBoard)
+		mustDo(t, e, Move{DCol: tt.dcol, DRow: tt.drow})
+		if col, row := e.Cursor(); col != tt.col || row != tt.row {
+			t.Errorf("Move{%d, %d}: cursor %d,%d, want %d,%d", tt.dcol, tt.drow, col, row, tt.col, tt.row)
+		}
+	}
+}
+
+func TestStartGameNames(t *testing.T) {
+	e, _ := inPhase(t, PhaseSetup)
+	mustDo(t, e, StartGame{Teams: []string{" Red ", "", "Blue"}})
+	var names []string
+	for _, team := range e.Teams() {
+		names = append(names, team.Name)
+	}
+	if want := []string{"Red", "Team 2", "Blue"}; !slices.Equal(names, want) {
+		t.Errorf("teams %q, want %q", names, want)
+	}
+}
+
+func TestRules(t *testing.T) {
+	rules := DefaultRules()
+	rules.NegativeScores = false
+	rules.ReopenPicked = true
+	e, _ := newTestEngine(t, rules)
+	mustDo(t, e,
+		StartGame{Teams: []string{"A", "B"}},
+		Adjust{Changes: []ScoreChange{{Team: 0, Op: '-', Value: 500}, {Team: 1, Op: '=', Value: -20}}},
+		Open{}, Back{}, Open{},
+	)
+	if a, b := e.Teams()[0].Score, e.Teams()[1].Score; a != 0 || b != 0 {
+		t.Errorf("scores %d, %d with negative scores off, want 0, 0", a, b)
+	}
+}
+
+func TestControl(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []ScoreChange
+		want    string   // team in control afterwards
+		events  []string // besides scores
+	}{
+		{"plus takes control", []ScoreChange{{Team: 1, Op: '+', Value: 100}}, "Blue", []string{"control Blue"}},
+		{"last plus wins", []ScoreChange{{Team: 1, Op: '+', Value: 100}, {Team: 2, Op: '+', Value: 100}}, "Green", []string{"control Green"}},
+		{"minus keeps control", []ScoreChange{{Team: 0, Op: '-', Value: 100}}, "Red", nil},
+		{"set keeps control", []ScoreChange{{Team: 2, Op: '=', Value: 900}}, "Red", nil},
+		{"plus for the team in control", []ScoreChange{{Team: 0, Op: '+', Value: 100}}, "Red", nil},
+	}
+	for _, tt := range tests {
+		e, rec := inPhase(t, PhaseQuestion)
+		mustDo(t, e, SetControl{Team: 0})
+		rec.take()
+		mustDo(t, e, Adjust{Changes: tt.changes})
+		if got := teamName(e.Control()); got != tt.want {
+			t.Errorf("%s: control %s, want %s", tt.name, got, tt.want)
+		}
+		events := rec.take()[1:] // after ScoresChanged
+		if !slices.Equal(events, tt.events) {
+			t.Errorf("%s: events %q, want %q", tt.name, events, tt.events)
+		}
+	}
+}
+
+func TestBuzzer(t *testing.T) {
+	e, rec := inPhase(t, PhaseQuestion)
+	mustDo(t, e, Buzz{Team: 1})
+	if err := e.Do(Buzz{Team: 2}); !errors.Is(err, ErrBuzzedIn) {
+		t.Fatalf("second buzz: %v, want ErrBuzzedIn", err)
+	}
+	// points taken from another team leave the buzz alone
+	mustDo(t, e, Adjust{Changes: []ScoreChange{{Team: 2, Op: '-', Value: 100}}})
+	if teamName(e.Buzzed()) != "Blue" {
+		t.Fatalf("buzz lost after another team's -")
+	}
+	// a wrong response opens the buzzers again
+	mustDo(t, e, Adjust{Changes: []ScoreChange{{Team: 1, Op: '-', Value: 100}}}, Buzz{Team: 2})
+	mustDo(t, e, MergeTeams{From: 2, Into: 0})
+	if teamName(e.Buzzed()) != "Red" {
+		t.Errorf("buzz not moved to the merged team, got %s", teamName(e.Buzzed()))
+	}
+	mustDo(t, e, AddTeam{Name: "Gold"}, RemoveTeam{Team: 0})
+	if e.Buzzed() != nil {
+		t.Errorf("buzz kept by a removed team")
+	}
+	want := []string{
+		"buzzer Blue",
+		"scores Green=-100",
+		"scores Blue=-100", "buzzer -",
+		"buzzer Green",
+		"teams merged Green into Red (now -100)", "buzzer Red",
+		"teams added team 3: Gold",
+		"teams removed Red", "buzzer -",
+	}
+	if got := rec.take(); !slices.Equal(got, want) {
+		t.Errorf("events\n%q\nwant\n%q", got, want)
+	}
+
+	// a new clue starts with open buzzers
+	mustDo(t, e, Buzz{Team: 0}, Back{}, Move{DCol: 1}, Open{})
+	if e.Buzzed() != nil {
+		t.Errorf("buzz carried over to the next clue")
+	}
+}
+
+func TestUndo(t *testing.T) {
+	e, rec := inPhase(t, PhaseQuestion)
+	mustDo(t, e,
+		Adjust{Changes: []ScoreChange{{Team: 0, Op: '+', Value: 100}}, Label: "first"},
+		Buzz{Team: 1},
+	)
+	saved := dump(e)
+	teams := slices.Clone(e.Teams())
+	mustDo(t, e,
+		Adjust{Changes: []ScoreChange{{Team: 1, Op: '-', Value: 100}, {Team: 2, Op: '+', Value: 300}}, Label: "second"},
+		RenameTeam{Team: 0, Name: "Crimson"},
+		MoveTeam{Team: 0, By: 2},
+		MergeTeams{From: 0, Into: 1},
+		AddTeam{Name: "Gold"},
+	)
+	rec.take()
+	for range 5 {
+		mustDo(t, e, Undo{})
+	}
+	if got := dump(e); got != saved {
+		t.Errorf("undo didn't restore the game:\n%s\nwant\n%s", got, saved)
+	}
+	// the same teams come back, so anyone holding one still has it
+	if !slices.Equal(e.Teams(), teams) {
+		t.Errorf("undo replaced the teams")
+	}
+	want := []string{"undone adding Gold", "undone merging Blue into Green", "undone moving Crimson", "undone renaming Red", "undone second"}
+	if got := rec.take(); !slices.Equal(got, want) {
+		t.Errorf("events %q, want %q", got, want)
+	}
+
+	// restored teams are the ones later commands act on
+	mustDo(t, e, Adjust{Changes: []ScoreChange{{Team: 0, Op: '+', Value: 1}}})
+	if e.Teams()[0].Score != 101 || e.Control() != e.Teams()[0] {
+		t.Errorf("restored team not live: score %d, control %s", e.Teams()[0].Score, teamName(e.Control()))
+	}
+}
+
+func TestUndoBuzz(t *testing.T) {
+	tests := []struct {
+		name       string
+		then       []Command // after Blue buzzes in and loses 100
+		wantBuzzed string    // - for open buzzers
+	}{
+		{"same clue", nil, "Blue"},
+		{"clue closed", []Command{Back{}}, "-"},
+		{"next clue", []Command{Back{}, Move{DCol: 1}, Open{}}, "-"},
+	}
+	for _, tt := range tests {
+		e, _ := inPhase(t, PhaseQuestion)
+		mustDo(t, e,
+			Buzz{Team: 1},
+			Adjust{Changes: []ScoreChange{{Team: 1, Op: '-', Value: 100}}},
+		)
+		if e.Buzzed() != nil {
+			t.Fatalf("%s: taking points from Blue didn't open the buzzers", tt.name)
+		}
+		mustDo(t, e, tt.then...)
+		mustDo(t, e, Undo{})
+		if got := teamName(e.Buzzed()); got != tt.wantBuzzed {
+			t.Errorf("%s: buzzed %q after undo, want %q", tt.name, got, tt.wantBuzzed)
+		}
+		if e.Teams()[1].Score != 0 {
+			t.Errorf("%s: score %d, want 0", tt.name, e.Teams()[1].Score)
+		}
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	e, _ := inPhase(t, PhaseBoard)
+	for range maxHistory + 10 {
+		mustDo(t, e, Adjust{Changes: []ScoreChange{{Team: 0, Op: '+', Value: 1}}})
+	}
+	n := 0
+	for e.CanUndo() {
+		mustDo(t, e, Undo{})
+		n++
+	}
+	if n != maxHistory || e.Teams()[0].Score != 10 {
+		t.Errorf("undid %d changes to score %d, want %d to 10", n, e.Teams()[0].Score, maxHistory)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	e, rec := newTestEngine(t, DefaultRules())
+	mustDo(t, e,
+		StartGame{Teams: []string{"Red", "Blue"}},
+		Move{DRow: 1},
+		Open{},
+		Reveal{},
+		Buzz{Team: 1},
+		Adjust{Changes: []ScoreChange{{Team: 1, Op: '+', Value: 200}}},
+		Reveal{},
+		Back{},
+		Select{Col: 2, Row: 2},
+		Undo{},
+	)
+	want := []string{
+		"teams 2 teams",
+		"phase board",
+		"cursor 0,1",
+		"opened q01",
+		"phase question",
+		"answer true",
+		"buzzer Blue",
+		"scores Blue=200",
+		"control Blue",
+		"answer false",
+		"phase board",
+		"cursor 2,2",
+		"undone score change",
+	}
+	if got := rec.take(); !slices.Equal(got, want) {
+		t.Errorf("events\n%q\nwant\n%q", got, want)
+	}
+	if e.LastValue() != 200 || e.Current() != nil || e.AnswerShown() {
+		t.Errorf("last value %d, current %v, answer %v after closing the clue", e.LastValue(), e.Current(), e.AnswerShown())
+	}
+}
@@ -0,0 +1,73 @@
+package engine
+
+import "github.com/maristcomputersociety/tuipardy/board"
+
+// Event describes a change made by a command
+type Event interface {
+	event()
+}
+
+// PhaseChanged is sent when the game moves to another phase
+type PhaseChanged struct {
+	Phase Phase
+}
+
+// CursorMoved is sent when the cursor lands on another cell
+type CursorMoved struct {
+	Col, Row int
+	Question *board.Question
+}
+
+// ClueOpened is sent when a clue is opened, before the phase change
+type ClueOpened struct {
+	Question *board.Question
+}
+
+// AnswerToggled is sent when the open clue switches between question and answer
+type AnswerToggled struct {
+	Question *board.Question
+	Shown    bool
+}
+
+// ScoreResult is a score change and the team's score after it
+type ScoreResult struct {
+	Team   *Team
+	Change ScoreChange
+}
+
+// ScoresChanged is sent when one or more scores change
+type ScoresChanged struct {
+	Results []ScoreResult
+}
+
+// ControlChanged is sent when another team gets the next pick
+type ControlChanged struct {
+	Team *Team // nil when no team is in control
+}
+
+// BuzzerChanged is sent when a team buzzes in, and when the buzzers open
+// again after a wrong response
+type BuzzerChanged struct {
+	Team *Team // nil when the buzzers are open
+}
+
+// TeamsChanged is sent when teams are added, removed, renamed, merged or
+// reordered
+type TeamsChanged struct {
+	What string // e.g. "renamed Red to Reds"
+}
+
+// Undone is sent when a change is undone
+type Undone struct {
+	What string // the change that was undone
+}
+
+func (PhaseChanged) event()   {}
+func (CursorMoved) event()    {}
+func (ClueOpened) event()     {}
+func (AnswerToggled) event()  {}
+func (ScoresChanged) event()  {}
+func (ControlChanged) event() {}
+func (BuzzerChanged) event()  {}
+func (TeamsChanged) event()   {}
+func (Undone) event()         {}
@@ -0,0 +1,113 @@
+package engine_test
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/maristcomputersociety/tuipardy/board"
+	"github.com/maristcomputersociety/tuipardy/engine"
+)
+
+// exampleBoard returns a full board with clues worth 100, 200 and 300
+func exampleBoard() *board.Board {
+	var csv strings.Builder
+	for _, cat := range []string{"Go", "Unix", "Networks", "Databases", "Compilers", "History"} {
+		for _, v := range []int{100, 200, 300} {
+			fmt.Fprintf(&csv, "%s,%d,A %s question for %d,An answer\n", cat, v, cat, v)
+		}
+	}
+	b, err := board.Read(strings.NewReader(csv.String()))
+	if err != nil {
+		log.Fatal(err)
+	}
+	return b
+}
+
+// A game with two teams where the first one takes the first clue
+func Example() {
+	e, err := engine.New(exampleBoard(), engine.DefaultRules())
+	if err != nil {
+		log.Fatal(err)
+	}
+	e.Subscribe(func(ev engine.Event) {
+		switch ev := ev.(type) {
+		case engine.ClueOpened:
+			fmt.Println("opened:", ev.Question.Q)
+		case engine.ScoresChanged:
+			for _, r := range ev.Results {
+				fmt.Printf("%s now has %d\n", r.Team.Name, r.Team.Score)
+			}
+		case engine.ControlChanged:
+			fmt.Println(ev.Team.Name, "picks next")
+		case engine.PhaseChanged:
+			fmt.Println("phase:", ev.Phase)
+		}
+	})
+	e.Do(engine.StartGame{Teams: []string{"Red", "Blue"}})
+	e.Do(engine.Open{})
+	e.Do(engine.Adjust{Changes: []engine.ScoreChange{{Team: 0, Op: '+', Value: e.LastValue()}}})
+	e.Do(engine.Back{})
+	fmt.Println(e.Do(engine.Open{}))
+	// Output:
+	// phase: board
+	// opened: A Compilers question for 100
+	// phase: question
+	// Red now has 100
+	// Red picks next
+	// phase: board
+	// already taken
+}
+
+func ExampleNew() {
+	_, err := engine.New(&board.Board{}, engine.DefaultRules())
+	fmt.Println(err)
+	// Output:
+	// board: expected 6 categories, got 0
+}
+
+func ExampleEngine_ParseScore() {
+	e, err := engine.New(exampleBoard(), engine.DefaultRules())
+	if err != nil {
+		log.Fatal(err)
+	}
+	e.Do(engine.StartGame{Teams: []string{"Red", "Blue", "C++"}})
+	e.Do(engine.Open{})
+
+	changes, err := e.ParseScore("1+,bl-200,c+++50,2=0")
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, c := range changes {
+		fmt.Printf("%s %c%d\n", e.Teams()[c.Team].Name, c.Op, c.Value)
+	}
+	_, err = e.ParseScore("1+100,4-100")
+	fmt.Println(err)
+	// Output:
+	// Red +100
+	// Blue -200
+	// C++ +50
+	// Blue =0
+	// no team 4, expected 1-3 (at column 7)
+}
+
+func ExampleUndo() {
+	e, err := engine.New(exampleBoard(), engine.DefaultRules())
+	if err != nil {
+		log.Fatal(err)
+	}
+	e.Do(engine.StartGame{Teams: []string{"Red", "Blue"}})
+	e.Do(engine.Adjust{Changes: []engine.ScoreChange{{Team: 1, Op: '+', Value: 300}}, Label: "Blue +300"})
+	e.Subscribe(func(ev engine.Event) {
+		if ev, ok := ev.(engine.Undone); ok {
+			fmt.Println("undid", ev.What)
+		}
+	})
+	e.Do(engine.Undo{})
+	fmt.Println(e.Teams()[1].Score, e.Control())
+	fmt.Println(e.Do(engine.Undo{}))
+	// Output:
+	// undid Blue +300
+	// 0 <nil>
+	// nothing to undo
+}
@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"slices"
+
+	"github.com/maristcomputersociety/tuipardy/board"
+)
+
+// maxHistory is how many team changes can be undone
+const maxHistory = 100
+
+// snapshot is the team list as it was before a change. Undo puts the same
+// Team values back, so anything holding on to a team still has it after
+// the change is undone.
+type snapshot struct {
+	teams   []*Team
+	saved   []Team          // each team's name and score
+	control *Team           // the team in control, or nil
+	buzzed  *Team           // the team that buzzed in, or nil
+	clue    *board.Question // the clue buzzed is for
+	what    string          // described when the change is undone
+}
+
+// pushHistory records the teams before a change described by what
+func (e *Engine) pushHistory(what string) {
+	snap := snapshot{teams: slices.Clone(e.teams), saved: make([]Team, len(e.teams)), control: e.control, buzzed: e.buzzed, clue: e.current, what: what}
+	for i, t := range e.teams {
+		snap.saved[i] = *t
+	}
+	e.history = append(e.history, snap)
+	if len(e.history) > maxHistory {
+		e.history = e.history[1:]
+	}
+}
+
+// Undo restores the teams and scores from before the last score or team change
+type Undo struct{}
+
+func (Undo) apply(e *Engine) error {
+	if len(e.history) == 0 {
+		return ErrNothingToUndo
+	}
+	snap := e.history[len(e.history)-1]
+	e.history = e.history[:len(e.history)-1]
+	for i, t := range snap.teams {
+		*t = snap.saved[i]
+	}
+	e.teams = snap.teams
+	e.control, e.buzzed = snap.control, nil
+	// a buzz only comes back on the clue it was for
+	if e.current != nil && e.current == snap.clue {
+		e.buzzed = snap.buzzed
+	}
+	e.emit(Undone{What: snap.what})
+	return nil
+}
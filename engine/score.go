@@ -0,0 +1,159 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ScoreChange is one score adjustment, e.g. 2+200
+type ScoreChange struct {
+	Team  int  // index into the team list
+	Op    byte // '+' or '-' to adjust, '=' to set
+	Value int
+}
+
+// scoreCmdError is a score command parse error at a byte offset of the input
+type scoreCmdError struct {
+	pos int
+	msg string
+}
+
+func (e *scoreCmdError) Error() string {
+	return fmt.Sprintf("%s (at column %d)", e.msg, e.pos+1)
+}
+
+// ParseScore parses a batch of score commands against the current teams; see
+// parseScoreCommands for the grammar
+func (e *Engine) ParseScore(text string) ([]ScoreChange, error) {
+	return parseScoreCommands(text, e.teams, e.lastValue)
+}
+
+// parseScoreCommands parses a comma separated batch of score commands:
+//
+//	<team><op>[value][,<team><op>[value]...]
+//
+// team is a 1-based team number or an unambiguous prefix of a team name, op
+// is + or - to adjust or = to set, and a missing value after + or - means
+// lastValue, the value of the current or last opened clue. Nothing is
+// returned unless every command in the batch parses.
+func parseScoreCommands(text string, teams []*Team, lastValue int) ([]ScoreChange, error) {
+	var changes []ScoreChange
+	pos := 0
+	for _, cmd := range strings.Split(text, ",") {
+		c, err := parseScoreCommand(cmd, pos, teams, lastValue)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+		pos += len(cmd) + 1
+	}
+	return changes, nil
+}
+
+// parseScoreCommand parses a single command starting at byte offset pos of
+// the whole input, which is used for error positions
+func parseScoreCommand(cmd string, pos int, teams []*Team, lastValue int) (ScoreChange, error) {
+	opAt := findScoreOp(cmd, teams)
+	if opAt < 0 {
+		return ScoreChange{}, &scoreCmdError{pos + len(cmd), "expected +, - or ="}
+	}
+	c := ScoreChange{Op: cmd[opAt]}
+
+	team, err := findTeam(strings.TrimSpace(cmd[:opAt]), teams)
+	if err != nil {
+		return ScoreChange{}, &scoreCmdError{pos, err.Error()}
+	}
+	c.Team = team
+
+	val := strings.TrimSpace(cmd[opAt+1:])
+	valPos := pos + opAt + 1 + strings.Index(cmd[opAt+1:], val)
+	negative := false
+	if c.Op == '=' && strings.HasPrefix(val, "-") {
+		negative = true
+		val = val[1:]
+		valPos++
+	}
+	val = strings.TrimPrefix(val, "$")
+	switch {
+	case val == "" && c.Op == '=':
+		return ScoreChange{}, &scoreCmdError{valPos, "expected a score after ="}
+	case val == "" && lastValue == 0:
+		return ScoreChange{}, &scoreCmdError{valPos, "no clue opened yet, give a value"}
+	case val == "":
+		c.Value = lastValue
+	default:
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 0 {
+			return ScoreChange{}, &scoreCmdError{valPos, fmt.Sprintf("bad value %q", val)}
+		}
+		c.Value = n
+	}
+	if negative {
+		c.Value = -c.Value
+	}
+	return c, nil
+}
+
+// findScoreOp returns the byte offset of a command's operator: the first +,
+// - or = that follows a team reference and is followed by nothing but a
+// value, so team names like "C++" and "Red-Team" can be used. Otherwise the
+// first operator is returned so the error is reported where it was before;
+// -1 means there is none.
+func findScoreOp(cmd string, teams []*Team) int {
+	for i := 0; i < len(cmd); i++ {
+		if strings.IndexByte("+-=", cmd[i]) < 0 || !isScoreValue(cmd[i], cmd[i+1:]) {
+			continue
+		}
+		if _, err := findTeam(strings.TrimSpace(cmd[:i]), teams); err == nil {
+			return i
+		}
+	}
+	return strings.IndexAny(cmd, "+-=")
+}
+
+// isScoreValue reports whether val is a value op accepts: empty or a number,
+// optionally with a $, and for = optionally negative
+func isScoreValue(op byte, val string) bool {
+	val = strings.TrimSpace(val)
+	if op == '=' {
+		val = strings.TrimPrefix(val, "-")
+	}
+	val = strings.TrimPrefix(val, "$")
+	for _, r := range val {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return op != '=' || val != ""
+}
+
+// findTeam resolves a team number or name prefix to an index into teams.
+// An exact name match wins over longer names sharing the prefix.
+func findTeam(ref string, teams []*Team) (int, error) {
+	if ref == "" {
+		return 0, fmt.Errorf("expected a team number or name")
+	}
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(teams) {
+			return 0, fmt.Errorf("no team %d, expected 1-%d", n, len(teams))
+		}
+		return n - 1, nil
+	}
+	found := -1
+	for i, t := range teams {
+		if strings.EqualFold(t.Name, ref) {
+			return i, nil
+		}
+		if strings.HasPrefix(strings.ToLower(t.Name), strings.ToLower(ref)) {
+			if found >= 0 {
+				return 0, fmt.Errorf("%q matches %s and %s", ref, teams[found].Name, t.Name)
+			}
+			found = i
+		}
+	}
+	if found < 0 {
+		return 0, fmt.Errorf("no team matches %q", ref)
+	}
+	return found, nil
+}
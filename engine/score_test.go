@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func teamsNamed(names ...string) []*Team {
+	teams := make([]*Team, len(names))
+	for i, name := range names {
+		teams[i] = &Team{Name: name}
+	}
+	return teams
+}
+
+func TestParseScoreCommands(t *testing.T) {
+	teams := teamsNamed("Red", "Blue", "C++", "Red-Team", "Équipe")
+	tests := []struct {
+		text      string
+		lastValue int
+		want      []ScoreChange
+		wantErr   string // substring, with the column
+	}{
+		{text: "1+200", want: []ScoreChange{{0, '+', 200}}},
+		{text: "2-", lastValue: 300, want: []ScoreChange{{1, '-', 300}}},
+		{text: "1+$400", want: []ScoreChange{{0, '+', 400}}},
+		{text: "1=0", want: []ScoreChange{{0, '=', 0}}},
+		{text: "2=-50", want: []ScoreChange{{1, '=', -50}}},
+		{text: "2=$-50", wantErr: `bad value "-50" (at column 3)`},
+		{text: " 1 + 200 , 2 - 100 ", want: []ScoreChange{{0, '+', 200}, {1, '-', 100}}},
+		{text: "bl+100", want: []ScoreChange{{1, '+', 100}}},
+		{text: "red-100", want: []ScoreChange{{0, '-', 100}}},
+		{text: "red-team-100", want: []ScoreChange{{3, '-', 100}}},
+		{text: "Red-Team+", lastValue: 200, want: []ScoreChange{{3, '+', 200}}},
+		{text: "c+++50", want: []ScoreChange{{2, '+', 50}}},
+		{text: "C+++", lastValue: 100, want: []ScoreChange{{2, '+', 100}}},
+		{text: "c++=7", want: []ScoreChange{{2, '=', 7}}},
+		{text: "éq+100", want: []ScoreChange{{4, '+', 100}}},
+		{text: "ÉQUIPE-1", want: []ScoreChange{{4, '-', 1}}},
+		{text: "1", wantErr: "expected +, - or = (at column 2)"},
+		{text: "6+100", wantErr: "no team 6, expected 1-5 (at column 1)"},
+		{text: "0+100", wantErr: "no team 0"},
+		{text: "+100", wantErr: "expected a team number or name (at column 1)"},
+		{text: "1+abc", wantErr: `bad value "abc" (at column 3)`},
+		{text: "1+-5", wantErr: `bad value "-5"`},
+		{text: "1=", wantErr: "expected a score after = (at column 3)"},
+		{text: "1+", wantErr: "no clue opened yet, give a value (at column 3)"},
+		{text: "1+100,9-100", wantErr: "no team 9, expected 1-5 (at column 7)"},
+		{text: "re+1", wantErr: `"re" matches Red and Red-Team`},
+		{text: "green+1", wantErr: `no team matches "green"`},
+		{text: "1+100,", wantErr: "expected +, - or = (at column 7)"},
+	}
+	for _, tt := range tests {
+		got, err := parseScoreCommands(tt.text, teams, tt.lastValue)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%q: error %v, want %q", tt.text, err, tt.wantErr)
+			}
+			if got != nil {
+				t.Errorf("%q: got changes %v along with an error", tt.text, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.text, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFindTeam(t *testing.T) {
+	teams := teamsNamed("Red", "Redwood", "Blue", "Über", "日本")
+	tests := []struct {
+		ref     string
+		want    int
+		wantErr string
+	}{
+		{ref: "1", want: 0},
+		{ref: "5", want: 4},
+		{ref: "6", wantErr: "no team 6"},
+		{ref: "-1", wantErr: "no team -1"},
+		{ref: "red", want: 0}, // exact beats the longer Redwood
+		{ref: "RED", want: 0},
+		{ref: "redw", want: 1},
+		{ref: "b", want: 2},
+		{ref: "ü", want: 3},
+		{ref: "ÜB", want: 3},
+		{ref: "日", want: 4},
+		{ref: "re", wantErr: `"re" matches Red and Redwood`},
+		{ref: "x", wantErr: `no team matches "x"`},
+		{ref: "", wantErr: "expected a team number or name"},
+		{ref: "Blue Team", wantErr: "no team matches"},
+	}
+	for _, tt := range tests {
+		got, err := findTeam(tt.ref, teams)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%q: got %d, %v, want error %q", tt.ref, got, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%q: got %d, %v, want %d", tt.ref, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseScoreUsesLastValue(t *testing.T) {
+	e, _ := inPhase(t, PhaseBoard)
+	if _, err := e.ParseScore("1+"); err == nil {
+		t.Errorf("1+ before any clue was opened parsed")
+	}
+	mustDo(t, e, Move{DRow: 2}, Open{}, Back{})
+	changes, err := e.ParseScore("gr+")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []ScoreChange{{2, '+', 300}}; !slices.Equal(changes, want) {
+		t.Errorf("got %v, want %v", changes, want)
+	}
+}
@@ -0,0 +1,13 @@
+package engine
+
+// Team is one team in the game and its score
+type Team struct {
+	Name  string
+	Score int
+}
+
+// team limits
+const (
+	MinTeams = 2
+	MaxTeams = 16
+)
@@ -0,0 +1,68 @@
+// Command quiz plays a board in the terminal as a plain question and answer
+// session for one player, as an example of using packages board and engine
+// without the tuipardy UI.
+//
+//	go run ./examples/quiz questions/board.csv
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/maristcomputersociety/tuipardy/board"
+	"github.com/maristcomputersociety/tuipardy/engine"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintf(os.Stderr, "usage: %s <board.csv>\n", os.Args[0])
+		os.Exit(2)
+	}
+	b, err := board.Load(os.Args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading board: %v\n", err)
+		os.Exit(1)
+	}
+
+	rules := engine.DefaultRules()
+	rules.MinTeams = 1
+	e, err := engine.New(b, rules)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	e.Subscribe(func(ev engine.Event) {
+		switch ev := ev.(type) {
+		case engine.ClueOpened:
+			fmt.Printf("\n%s for %d\n%s\n> ", ev.Question.Category, ev.Question.Value, ev.Question.Q)
+		case engine.ScoresChanged:
+			fmt.Printf("score: %d\n", ev.Results[0].Team.Score)
+		}
+	})
+	if err := e.Do(engine.StartGame{Teams: []string{"you"}}); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	in := bufio.NewScanner(os.Stdin)
+	for col := range b.Categories {
+		for row := range board.QuestionsPerCategory {
+			e.Do(engine.Select{Col: col, Row: row})
+			e.Do(engine.Open{})
+			if !in.Scan() {
+				return
+			}
+			q := e.Current()
+			op := byte('-')
+			if strings.EqualFold(strings.TrimSpace(in.Text()), q.A) {
+				op = '+'
+			} else {
+				fmt.Printf("the answer was: %s\n", q.A)
+			}
+			e.Do(engine.Adjust{Changes: []engine.ScoreChange{{Team: 0, Op: op, Value: q.Value}}})
+			e.Do(engine.Back{})
+		}
+	}
+}
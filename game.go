@@ -2,87 +2,278 @@ package main
 
 import (
 	"fmt"
-	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/maristcomputersociety/tuipardy/engine"
 )
 
 type Game struct {
-	s              tcell.Screen
-	board          *Board
-	phase          int
-	teams          []*Team
-	minTeams       int
-	maxTeams       int
-	prompt         string
-	inputBuf       string // command buffer
-	msg            string // status line
-	cursorCol      int
-	cursorRow      int
-	curQ           *Question
-	showAnswer     bool
-	lastClick      time.Time
-	lastCell       [2]int
-	imageRenderer  *ImageRenderer
-	imageSupported bool
-	textToRender   string // text content to render via stdout
-	textStyle      tcell.Style
-	textAreaX      int
-	textAreaY      int
-	textAreaW      int
-	textAreaH      int
+	clueView
+	e            *engine.Engine
+	phase        int      // screen shown, follows the engine once set up
+	setupTeams   []string // names entered so far during setup
+	setupCount   int      // number of teams being set up
+	prompt       string
+	inputBuf     string // command buffer
+	typingCmd    bool   // inputBuf holds a score command being typed
+	cmdErr       error  // why the typed score command didn't apply
+	msg          string // status line
+	lastClick    time.Time
+	lastCell     [2]int
+	narrator     *Narrator // optional plain text narration of state changes
+	cfg          *Config
+	clueDeadline time.Time // when the clue timer runs out, zero if none
+	timeUp       bool
+	showHelp     bool             // key binding overlay is open
+	teamMgr      *teamManager     // team overlay, nil when closed
+	confirmQuit  bool             // quit was pressed, waiting for y
+	running      chan struct{}    // closed once Run has set up the screen
+	stopped      chan struct{}    // closed once Run has returned
+	remotes      []*remote        // players connected over SSH
+	control      *controlServer   // control socket, nil when not listening
+	practice     *practiceSession // solo practice game, nil when hosting
 }
 
-func NewGame(b *Board) *Game {
-	imageSupported := IsImageSupported()
-	var imageRenderer *ImageRenderer
-	if imageSupported {
-		imageRenderer = NewImageRenderer()
+// NewGame sets up a game for a board. cfg must have passed validate.
+func NewGame(b *Board, cfg *Config) (*Game, error) {
+	e, err := engine.New(b, cfg.EngineRules())
+	if err != nil {
+		return nil, err
+	}
+	keys, _ := NewKeymap(cfg.Keys)
+
+	g := &Game{
+		clueView: newClueView(cfg.Images, keys),
+		e:        e,
+		phase:    PhaseSetupNumTeams,
+		prompt:   fmt.Sprintf("enter number of teams (%d-%d): ", cfg.MinTeams, cfg.MaxTeams),
+		cfg:      cfg,
+		running:  make(chan struct{}),
+		stopped:  make(chan struct{}),
 	}
+	g.e.Subscribe(g.onEvent)
 
-	return &Game{
-		board:          b,
-		phase:          PhaseSetupNumTeams,
-		minTeams:       MinTeams,
-		maxTeams:       MaxTeams,
-		imageRenderer:  imageRenderer,
-		imageSupported: imageSupported,
+	// configured teams skip the setup prompts
+	if len(cfg.Teams) > 0 {
+		g.do(engine.StartGame{Teams: cfg.Teams})
 	}
+	return g, nil
 }
 
-func (g *Game) Run() error {
-	s, err := tcell.NewScreen()
-	if err != nil {
-		return err
+// do applies a command to the engine, showing why if it can't be done
+func (g *Game) do(cmd engine.Command) bool {
+	if err := g.e.Do(cmd); err != nil {
+		g.flashMsg("%v", err)
+		return false
 	}
+	return true
+}
+
+// onEvent updates the screen state and narration for an engine event
+func (g *Game) onEvent(ev engine.Event) {
+	switch ev := ev.(type) {
+	case engine.PhaseChanged:
+		switch ev.Phase {
+		case engine.PhaseBoard:
+			if g.phase == PhaseQuestion {
+				g.narrate("Back to board. %s", g.teamSummary())
+			} else {
+				g.msg = g.boardHint()
+				g.narrate("Game started. %s", g.teamSummary())
+			}
+			g.phase = PhaseBoard
+			g.narrateCursor()
+			if g.practice != nil && g.practiceFinished() {
+				g.showSummary()
+			}
+		case engine.PhaseQuestion:
+			g.phase = PhaseQuestion
+		}
+	case engine.CursorMoved:
+		g.narrateCursor()
+	case engine.ClueOpened:
+		q := ev.Question
+		g.clueScroll = 0
+		if g.practice != nil {
+			g.practice.input, g.practice.judged = "", false
+		}
+		g.msg = g.questionHint()
+		g.timeUp = false
+		g.clueDeadline = time.Time{}
+		if t := g.cfg.Timers.Clue.Duration; t > 0 {
+			g.clueDeadline = time.Now().Add(t)
+		}
+		g.narrate("%s for %d. Question: %s", q.Category, q.Value, clueText(q.Q))
+	case engine.AnswerToggled:
+		g.clueScroll = 0
+		g.msg = g.questionHint()
+		if ev.Shown {
+			g.narrate("Answer: %s", clueText(ev.Question.A))
+		} else {
+			g.narrate("Question: %s", clueText(ev.Question.Q))
+		}
+	case engine.ScoresChanged:
+		done := make([]string, len(ev.Results))
+		for i, r := range ev.Results {
+			done[i] = fmt.Sprintf("%s %c%d (now %d)", r.Team.Name, r.Change.Op, r.Change.Value, r.Team.Score)
+		}
+		g.flashMsg("adjusted %s", strings.Join(done, ", "))
+	case engine.ControlChanged:
+		if ev.Team != nil {
+			g.narrate("%s picks", ev.Team.Name)
+		}
+	case engine.BuzzerChanged:
+		if ev.Team != nil {
+			// the status line names the team while it's in
+			g.msg = g.questionHint()
+			g.narrate("%s buzzed in", ev.Team.Name)
+			if g.s != nil {
+				g.s.Beep()
+			}
+		} else {
+			g.flashMsg("buzzers open")
+		}
+	case engine.TeamsChanged:
+		if g.phase == PhaseBoard || g.phase == PhaseQuestion {
+			g.flashMsg("%s", ev.What)
+		}
+	case engine.Undone:
+		g.flashMsg("undid %s. %s", ev.What, g.teamSummary())
+	}
+}
+
+// boardHint is the status line shown on the board
+func (g *Game) boardHint() string {
+	if g.practice != nil {
+		return fmt.Sprintf("arrows to move, %s to open, %s to finish, %s for help", g.keyNames(ActionOpen), g.keyNames(ActionQuit), g.keyNames(ActionHelp))
+	}
+	return fmt.Sprintf("arrows to move, %s to open, <teamnum><+ | -><score> to modify score, %s for help", g.keyNames(ActionOpen), g.keyNames(ActionHelp))
+}
+
+// Run plays the game on s until the players quit. s is initialized here and
+// finalized on return.
+func (g *Game) Run(s tcell.Screen) error {
+	defer close(g.stopped)
 	if err := s.Init(); err != nil {
 		return err
 	}
 	defer s.Fini()
-	g.s = s
-	g.prompt = fmt.Sprintf("enter number of teams (%d-%d): ", g.minTeams, g.maxTeams)
+	g.setScreen(s)
+	close(g.running)
+	defer g.dropRemotes()
+	if g.control != nil {
+		defer g.control.close()
+	}
 
-	for {
-		g.draw()
-		if ev := s.PollEvent(); ev != nil {
-			switch e := ev.(type) {
-			case *tcell.EventResize:
-				s.Sync()
-			case *tcell.EventKey:
-				if done := g.handleKey(e); done {
-					return nil
+	// tick once a second so the clue timer counts down on screen
+	quit := make(chan struct{})
+	defer close(quit)
+	if g.cfg.Timers.Clue.Duration > 0 {
+		go func() {
+			t := time.NewTicker(time.Second)
+			defer t.Stop()
+			for {
+				select {
+				case <-t.C:
+					s.PostEvent(tcell.NewEventInterrupt(nil))
+				case <-quit:
+					return
 				}
-			case *tcell.EventMouse:
-				g.handleMouse(e)
 			}
+		}()
+	}
+
+	for {
+		g.draw()
+		g.drawRemotes()
+		if ev := s.PollEvent(); ev != nil && g.handleEvent(ev) {
+			return nil
 		}
 	}
 }
 
+// postEvent hands ev from another goroutine to Run's loop, waiting for room
+// in the event queue. It gives up and returns false once Run has returned,
+// as nothing takes events from the queue any more.
+func (g *Game) postEvent(ev tcell.Event) bool {
+	for {
+		select {
+		case <-g.stopped:
+			return false
+		default:
+		}
+		if g.s.PostEvent(ev) == nil {
+			return true
+		}
+		select {
+		case <-g.stopped:
+			return false
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// handleEvent handles one screen event, reporting whether the game is over
+func (g *Game) handleEvent(ev tcell.Event) bool {
+	switch e := ev.(type) {
+	case *tcell.EventResize:
+		g.s.Sync()
+		g.pt.Invalidate()
+	case *tcell.EventKey:
+		return g.handleKey(e)
+	case *tcell.EventMouse:
+		g.handleMouse(e)
+	case *tcell.EventInterrupt:
+		g.tickClueTimer()
+	case *remoteEvent:
+		g.handleRemote(e)
+	case *controlEvent:
+		g.handleControl(e)
+	}
+	return false
+}
+
+// clueTimeLeft returns the time left on the clue timer, and false when no
+// timer is running
+func (g *Game) clueTimeLeft() (time.Duration, bool) {
+	if g.phase != PhaseQuestion || g.clueDeadline.IsZero() || g.e.AnswerShown() {
+		return 0, false
+	}
+	return max(0, time.Until(g.clueDeadline)), true
+}
+
+// tickClueTimer announces the end of the clue timer the first time it is seen
+func (g *Game) tickClueTimer() {
+	left, ok := g.clueTimeLeft()
+	if !ok || left > 0 || g.timeUp {
+		return
+	}
+	g.timeUp = true
+	g.flashMsg("time's up!")
+	g.s.Beep()
+}
+
 func (g *Game) handleKey(e *tcell.EventKey) bool {
 	key, r := e.Key(), e.Rune()
+	if g.showHelp {
+		return g.handleHelpKey(key)
+	}
+	if g.confirmQuit {
+		return g.handleConfirmQuit(key, r)
+	}
+	if g.practice != nil && g.phase == PhaseQuestion && !g.practice.judged {
+		return g.handlePracticeInput(key, r)
+	}
+	if g.typingCmd {
+		return g.handleScoreInput(key, r)
+	}
+	if g.teamMgr != nil {
+		return g.handleTeamKey(key, r)
+	}
 	switch g.phase {
 	case PhaseSetupNumTeams:
 		return g.handleSetupNumTeams(key, r)
@@ -92,6 +283,8 @@ func (g *Game) handleKey(e *tcell.EventKey) bool {
 		return g.handleBoardKey(key, r)
 	case PhaseQuestion:
 		return g.handleQuestionKey(key, r)
+	case PhaseSummary:
+		return true
 	}
 	return false
 }
@@ -101,18 +294,18 @@ func (g *Game) handleSetupNumTeams(key tcell.Key, r rune) bool {
 	case tcell.KeyEsc, tcell.KeyCtrlC:
 		return true
 	case tcell.KeyEnter:
-		if n, err := strconv.Atoi(g.inputBuf); err == nil && n >= g.minTeams && n <= g.maxTeams {
-			g.teams = make([]*Team, 0, n)
+		rules := g.e.Rules()
+		if n, err := strconv.Atoi(g.inputBuf); err == nil && n >= rules.MinTeams && n <= rules.MaxTeams {
+			g.setupCount = n
 			g.inputBuf = ""
-			g.prompt = fmt.Sprintf("enter name for Team %d: ", len(g.teams)+1)
+			g.prompt = fmt.Sprintf("enter name for Team %d: ", len(g.setupTeams)+1)
 			g.phase = PhaseSetupTeamNames
+			g.narrate("%d teams. %s", n, g.prompt)
 		} else {
-			g.flashMsg("invalid number; please enter %d-%d", g.minTeams, g.maxTeams)
+			g.flashMsg("invalid number; please enter %d-%d", rules.MinTeams, rules.MaxTeams)
 		}
 	case tcell.KeyBackspace, tcell.KeyBackspace2:
-		if len(g.inputBuf) > 0 {
-			g.inputBuf = g.inputBuf[:len(g.inputBuf)-1]
-		}
+		g.inputBuf = dropLastCluster(g.inputBuf)
 	default:
 		if r >= '0' && r <= '9' {
 			g.inputBuf += string(r)
@@ -128,20 +321,18 @@ func (g *Game) handleSetupTeamNames(key tcell.Key, r rune) bool {
 	case tcell.KeyEnter:
 		name := trimSpaces(g.inputBuf)
 		if name == "" {
-			name = fmt.Sprintf("Team %d", len(g.teams)+1)
+			name = fmt.Sprintf("Team %d", len(g.setupTeams)+1)
 		}
-		g.teams = append(g.teams, &Team{Name: name})
+		g.setupTeams = append(g.setupTeams, name)
 		g.inputBuf = ""
-		if len(g.teams) == cap(g.teams) {
-			g.phase = PhaseBoard
-			g.msg = "arrows to move, enter to open, space to reveal, <teamnum><+ | -><score> to modify score"
+		if len(g.setupTeams) == g.setupCount {
+			g.do(engine.StartGame{Teams: g.setupTeams})
 		} else {
-			g.prompt = fmt.Sprintf("enter name for Team %d: ", len(g.teams)+1)
+			g.prompt = fmt.Sprintf("enter name for Team %d: ", len(g.setupTeams)+1)
+			g.narrate("Team %d is %s. %s", len(g.setupTeams), name, g.prompt)
 		}
 	case tcell.KeyBackspace, tcell.KeyBackspace2:
-		if len(g.inputBuf) > 0 {
-			g.inputBuf = g.inputBuf[:len(g.inputBuf)-1]
-		}
+		g.inputBuf = dropLastCluster(g.inputBuf)
 	default:
 		if r != 0 {
 			g.inputBuf += string(r)
@@ -151,262 +342,203 @@ func (g *Game) handleSetupTeamNames(key tcell.Key, r rune) bool {
 }
 
 func (g *Game) handleBoardKey(key tcell.Key, r rune) bool {
-	switch key {
-	case tcell.KeyCtrlC:
+	if key == tcell.KeyCtrlC {
 		return true
-	case tcell.KeyRune:
-		if r == 'q' || r == 'Q' {
-			return true
-		}
-		if r == 'h' {
-			g.move(-1, 0)
-			return false
-		}
-		if r == 'l' {
-			g.move(+1, 0)
-			return false
-		}
-		if r == 'k' {
-			g.move(0, -1)
-			return false
-		}
-		if r == 'j' {
-			g.move(0, +1)
-			return false
-		}
-		if (r >= '0' && r <= '9') || r == '+' || r == '-' {
-			g.inputBuf += string(r)
-			return false
-		}
-	case tcell.KeyEnter:
-		if g.inputBuf != "" {
-			if applyScoreCommand(g, g.inputBuf) {
-				g.inputBuf = ""
-			} else {
-				g.flashMsg("invalid score command. use format: <team 1-%d><+|-><value>", len(g.teams))
-				g.inputBuf = ""
-			}
-		} else {
-			g.openSelected()
-		}
+	}
+
+	if g.practice == nil && g.startScoreCommand(key, r) {
 		return false
-	case tcell.KeyEsc:
-		g.inputBuf = ""
+	}
+
+	action := g.keys.Action(PhaseBoard, key, r)
+	if g.practice != nil && slices.Contains(practiceOff, action) {
 		return false
-	case tcell.KeyUp:
-		g.move(0, -1)
-	case tcell.KeyDown:
-		g.move(0, +1)
-	case tcell.KeyLeft:
-		g.move(-1, 0)
-	case tcell.KeyRight:
-		g.move(+1, 0)
+	}
+	switch action {
+	case ActionQuit:
+		g.confirmQuit = true
+		g.flashMsg("quit? press y to confirm, any other key to keep playing.")
+	case ActionHelp:
+		g.showHelp = true
+	case ActionTeams:
+		g.openTeamManager()
+	case ActionUndo:
+		g.do(engine.Undo{})
+	case ActionControl:
+		g.passControl()
+	case ActionLeft:
+		g.do(engine.Move{DCol: -1})
+	case ActionRight:
+		g.do(engine.Move{DCol: +1})
+	case ActionUp:
+		g.do(engine.Move{DRow: -1})
+	case ActionDown:
+		g.do(engine.Move{DRow: +1})
+	case ActionOpen:
+		g.do(engine.Open{})
 	}
 	return false
 }
 
 func (g *Game) handleQuestionKey(key tcell.Key, r rune) bool {
-	switch key {
-	case tcell.KeyCtrlC:
+	if key == tcell.KeyCtrlC {
 		return true
-	case tcell.KeyEsc:
-		g.clearImage()
-		g.curQ = nil
-		g.showAnswer = false
-		g.phase = PhaseBoard
-	case tcell.KeyEnter, tcell.KeyRune:
-		if key == tcell.KeyRune && r != ' ' {
-			return false
-		}
-		g.showAnswer = !g.showAnswer
-		if g.showAnswer {
-			g.msg = "showing answer. press space/enter to show question again, esc to return."
-		} else {
-			g.msg = "press space/enter to reveal answer, esc to return."
-		}
+	}
+	if g.practice == nil && g.startScoreCommand(key, r) {
+		return false
+	}
+
+	switch g.keys.Action(PhaseQuestion, key, r) {
+	case ActionHelp:
+		g.showHelp = true
+	case ActionBack:
+		g.do(engine.Back{})
+	case ActionScrollUp:
+		g.scrollClue(-g.cluePage)
+	case ActionScrollDown:
+		g.scrollClue(g.cluePage)
+	case ActionReveal:
+		g.do(engine.Reveal{})
 	}
 	return false
 }
 
-func (g *Game) move(dc, dr int) {
-	cols := len(g.board.Categories)
-	rows := QuestionsPerCategory
-	g.cursorCol = (g.cursorCol + dc + cols) % cols
-	g.cursorRow = (g.cursorRow + dr + rows) % rows
+// handleConfirmQuit quits on y or a second press of quit, and goes back to
+// the game on anything else
+func (g *Game) handleConfirmQuit(key tcell.Key, r rune) bool {
+	g.confirmQuit = false
+	if key == tcell.KeyCtrlC || (key == tcell.KeyRune && (r == 'y' || r == 'Y')) ||
+		g.keys.Action(g.phase, key, r) == ActionQuit {
+		if g.practice != nil && key != tcell.KeyCtrlC {
+			g.showSummary()
+			return false
+		}
+		return true
+	}
+	g.flashMsg("%s", g.boardHint())
+	return false
 }
 
-func (g *Game) openSelected() {
-	cat := g.board.Categories[g.cursorCol]
-	q := cat.Questions[g.cursorRow]
-	if q.Picked {
-		g.flashMsg("Already taken.")
-		return
+// passControl hands the next pick to the following team, for when the host
+// overrides who is in control
+func (g *Game) passControl() {
+	teams := g.e.Teams()
+	next := (g.e.TeamIndex(g.e.Control()) + 1) % len(teams)
+	if g.do(engine.SetControl{Team: next}) {
+		g.msg = teams[next].Name + " picks"
 	}
-	g.curQ = q
-	g.showAnswer = false
-	q.Picked = true
-	g.phase = PhaseQuestion
-	g.msg = "press space/enter to reveal answer, esc to return."
 }
 
-// Regex for score commands: <teamNum><+|-><value>
-var scoreCmdRe = regexp.MustCompile(`^([1-8])([\+\-])(\d+)$`)
-
-// applyScoreCommand parses and applies score adjustment
-func applyScoreCommand(g *Game, buf string) bool {
-	m := scoreCmdRe.FindStringSubmatch(buf)
-	if m == nil {
-		return false
+// questionHint is the status line shown on the question screen
+func (g *Game) questionHint() string {
+	if g.e.AnswerShown() {
+		return fmt.Sprintf("showing answer. press %s to show question again, %s to return, %s for help.", g.keyNames(ActionReveal), g.keyNames(ActionBack), g.keyNames(ActionHelp))
 	}
+	return fmt.Sprintf("press %s to reveal answer, %s to return, %s for help.", g.keyNames(ActionReveal), g.keyNames(ActionBack), g.keyNames(ActionHelp))
+}
 
-	idx, _ := strconv.Atoi(m[1])
-	sign := m[2]
-	val, _ := strconv.Atoi(m[3])
+// keyNames lists the keys bound to an action for use in hints
+func (g *Game) keyNames(action string) string { return g.keys.Names(action) }
 
-	teamIdx := idx - 1
-	if teamIdx < 0 || teamIdx >= len(g.teams) {
+// startScoreCommand starts typing a score command on a digit, or on ':' for
+// commands naming a team
+func (g *Game) startScoreCommand(key tcell.Key, r rune) bool {
+	if key != tcell.KeyRune || !(r >= '0' && r <= '9' || r == ':') {
 		return false
 	}
-
-	if sign == "+" {
-		g.teams[teamIdx].Score += val
-	} else {
-		g.teams[teamIdx].Score -= val
+	g.typingCmd = true
+	g.cmdErr = nil
+	g.inputBuf = ""
+	if r != ':' {
+		g.inputBuf = string(r)
 	}
-
-	g.flashMsg("adjusted %s: %c%d", g.teams[teamIdx].Name, sign[0], val)
 	return true
 }
 
-func (g *Game) flashMsg(format string, args ...any) {
-	g.msg = fmt.Sprintf(format, args...)
-}
-
-// renderImageAfterShow renders image using Kitty protocol
-func (g *Game) renderImageAfterShow() {
-	if g.imageRenderer == nil || g.curQ == nil || g.curQ.ImagePath == "" {
-		return
+// handleScoreInput edits the score command being typed. Every rune goes into
+// the command so team names can be typed; Enter applies it and cancel (Esc)
+// throws it away.
+func (g *Game) handleScoreInput(key tcell.Key, r rune) bool {
+	switch {
+	case key == tcell.KeyCtrlC:
+		return true
+	case key == tcell.KeyEnter:
+		if g.inputBuf == "" {
+			g.typingCmd = false
+			return false
+		}
+		if err := g.applyScoreCommands(g.inputBuf); err != nil {
+			// keep the command so it can be fixed
+			g.cmdErr = err
+			g.narrate("score command error: %v", err)
+			return false
+		}
+		g.inputBuf = ""
+		g.typingCmd = false
+	case key == tcell.KeyBackspace || key == tcell.KeyBackspace2:
+		g.inputBuf = dropLastCluster(g.inputBuf)
+		g.cmdErr = nil
+	case key == tcell.KeyRune:
+		g.inputBuf += string(r)
+		g.cmdErr = nil
+	case g.keys.Action(PhaseBoard, key, r) == ActionCancel:
+		g.inputBuf = ""
+		g.cmdErr = nil
+		g.typingCmd = false
 	}
+	return false
+}
 
-	w, h := g.s.Size()
-	questionAreaY := QuestionAreaY
-	questionAreaH := h - questionAreaY - StatusBarHeight
-	imageHeight := questionAreaH * ImageHeightRatio / 100
-
-	// define the image area boundaries
-	imageAreaY := questionAreaY
-	imageAreaHeight := imageHeight
-	imageAreaWidth := w - 4 // leave some padding on sides
-
-	// calculate the midpoint of the designated image area
-	areaMidX := w / 2
-	areaMidY := imageAreaY + imageAreaHeight/2
-
-	imgWidth, imgHeight, err := g.imageRenderer.GetImageBounds(g.curQ.ImagePath)
+// applyScoreCommands parses a batch of score commands and applies all of
+// them, or none if any fails to parse
+func (g *Game) applyScoreCommands(text string) error {
+	changes, err := g.e.ParseScore(text)
 	if err != nil {
-		return
-	}
-
-	estimatedCellWidth := imgWidth / 10
-	estimatedCellHeight := imgHeight / 20
-
-	// if estimated size exceeds the available area, scale down proportionally
-	if estimatedCellWidth > imageAreaWidth {
-		scale := float64(imageAreaWidth) / float64(estimatedCellWidth)
-		estimatedCellWidth = imageAreaWidth
-		estimatedCellHeight = int(float64(estimatedCellHeight) * scale)
-	}
-	if estimatedCellHeight > imageAreaHeight {
-		scale := float64(imageAreaHeight) / float64(estimatedCellHeight)
-		estimatedCellHeight = imageAreaHeight
-		estimatedCellWidth = int(float64(estimatedCellWidth) * scale)
-	}
-
-	// calculate cursor position to center the image's midpoint on the area's midpoint
-	cursorX := areaMidX - (estimatedCellWidth / 2)
-	cursorY := areaMidY - (estimatedCellHeight / 2)
-
-	if cursorX < 2 {
-		cursorX = 2
-	}
-	if cursorY < imageAreaY {
-		cursorY = imageAreaY
-	}
-	if cursorX+estimatedCellWidth > w-2 { // ensure image doesn't go off right edge
-		cursorX = w - 2 - estimatedCellWidth
-	}
-	if cursorY+estimatedCellHeight > imageAreaY+imageAreaHeight { // ensure image doesn't go off bottom
-		cursorY = imageAreaY + imageAreaHeight - estimatedCellHeight
-	}
-
-	fmt.Printf("\x1b[s")
-	fmt.Printf("\x1b[%d;%dH", cursorY, cursorX)
-
-	imageData, err := g.imageRenderer.RenderImageToString(g.curQ.ImagePath, 0, 0)
-	if err == nil && imageData != "" {
-		fmt.Print(imageData)
+		return err
 	}
+	return g.e.Do(engine.Adjust{Changes: changes, Label: "score change " + text})
+}
 
-	fmt.Printf("\x1b[u")
+// flashMsg sets the status line, which is narrated as well
+func (g *Game) flashMsg(format string, args ...any) {
+	g.msg = fmt.Sprintf(format, args...)
+	g.narrate("%s", g.msg)
 }
 
-func (g *Game) clearImage() {
-	if g.imageRenderer == nil {
-		return
+// setNarrator starts narrating the game on n. Configured teams start the
+// game before there is a narrator, so where it stands is described first.
+func (g *Game) setNarrator(n *Narrator) {
+	g.narrator = n
+	switch g.phase {
+	case PhaseSetupNumTeams:
+		g.narrate("%s", g.prompt)
+	case PhaseBoard:
+		g.narrate("Game started. %s", g.teamSummary())
+		g.narrateCursor()
 	}
+}
 
-	fmt.Print("\x1b_Ga=d\x1b\\")
+// narrate describes a state change on the narration channel, if any
+func (g *Game) narrate(format string, args ...any) {
+	g.narrator.Say(format, args...)
 }
 
-// renderTextAfterShow renders text using Kitty protocol
-func (g *Game) renderTextAfterShow() {
-	if g.textToRender == "" {
+// narrateCursor describes the board cell under the cursor
+func (g *Game) narrateCursor() {
+	q := g.e.Board().At(g.e.Cursor())
+	if q.Picked {
+		g.narrate("%s, %d, taken", q.Category, q.Value)
 		return
 	}
+	g.narrate("%s, %d", q.Category, q.Value)
+}
 
-	maxLineWidth := g.textAreaW/2 - 4
-	lines := wrapText(g.textToRender, maxLineWidth)
-
-	lineSpacing := 2
-	startY := g.textAreaY + (g.textAreaH-len(lines)*lineSpacing)/2
-	if startY < g.textAreaY {
-		startY = g.textAreaY
-	}
-
-	fmt.Printf("\x1b[s")
-
-	// to stdout
-	for i, textLine := range lines {
-		lineY := startY + i*lineSpacing
-		if lineY >= g.textAreaY+g.textAreaH {
-			break
-		}
-
-		scaledTextWidth := len(textLine) * 2
-		cx := g.textAreaX + g.textAreaW/2 - scaledTextWidth/2
-		if cx < g.textAreaX {
-			cx = g.textAreaX
-		}
-
-		fmt.Printf("\x1b[%d;%dH", lineY, cx)
-
-		// extract colors from tcell style
-		fg, _, _ := g.textStyle.Decompose()
-
-		fmt.Print("\x1b[48;5;234m") // dark gray background
-		if fg == tcell.ColorLightGreen || fg == tcell.ColorGreen {
-			fmt.Print("\x1b[38;5;46m") // bright green for answers
-		} else {
-			fmt.Print("\x1b[38;5;231m") // bright white for questions
-		}
-
-		// kitty text scaling
-		fmt.Printf("\x1b]66;s=2;%s\x07", textLine)
-
-		fmt.Print("\x1b[0m")
+// teamSummary lists every team with its score
+func (g *Game) teamSummary() string {
+	teams := g.e.Teams()
+	parts := make([]string, len(teams))
+	for i, t := range teams {
+		parts[i] = fmt.Sprintf("%s %d", t.Name, t.Score)
 	}
-
-	fmt.Printf("\x1b[u")
-
-	g.textToRender = ""
+	return "Scores: " + strings.Join(parts, ", ") + "."
 }
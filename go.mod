@@ -4,14 +4,16 @@ go 1.24.4
 
 require (
 	github.com/BourgeoisBear/rasterm v1.1.1
+	github.com/BurntSushi/toml v1.6.0
 	github.com/gdamore/tcell/v2 v2.9.0
+	github.com/rivo/uniseg v0.4.3
+	golang.org/x/crypto v0.41.0
 )
 
 require (
 	github.com/gdamore/encoding v1.0.1 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
 	github.com/mattn/go-runewidth v0.0.16 // indirect
-	github.com/rivo/uniseg v0.4.3 // indirect
 	golang.org/x/sys v0.35.0 // indirect
 	golang.org/x/term v0.34.0 // indirect
 	golang.org/x/text v0.28.0 // indirect
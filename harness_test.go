@@ -0,0 +1,370 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/maristcomputersociety/tuipardy/board"
+	"github.com/maristcomputersociety/tuipardy/engine"
+	"github.com/rivo/uniseg"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/ui")
+
+// TestGolden plays each testdata/ui/*.script on questions/board.csv and
+// compares its snapshots with the .golden file next to it. The game uses the
+// default config, so user and board config files can't change the result.
+// Run with -update after an intended change to the screen and review the diff.
+func TestGolden(t *testing.T) {
+	scripts, err := filepath.Glob(filepath.Join("testdata", "ui", "*.script"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scripts) == 0 {
+		t.Fatal("no scripts in testdata/ui")
+	}
+	for _, path := range scripts {
+		name := strings.TrimSuffix(filepath.Base(path), ".script")
+		t.Run(name, func(t *testing.T) {
+			g, err := NewGame(loadSampleBoard(t), testConfig())
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			var got bytes.Buffer
+			if err := RunScript(g, f, &got); err != nil {
+				t.Fatalf("%s: %v", path, err)
+			}
+
+			golden := strings.TrimSuffix(path, ".script") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, got.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got.Bytes(), want) {
+				t.Errorf("%s differs from %s, rerun with -update if the change is intended:\n%s", path, golden, lineDiff(string(want), got.String()))
+			}
+		})
+	}
+}
+
+// lineDiff lists the lines that differ between want and got, with their line
+// numbers
+func lineDiff(want, got string) string {
+	wl, gl := strings.Split(want, "\n"), strings.Split(got, "\n")
+	var b strings.Builder
+	for i := range max(len(wl), len(gl)) {
+		var w, g string
+		if i < len(wl) {
+			w = wl[i]
+		}
+		if i < len(gl) {
+			g = gl[i]
+		}
+		if w != g {
+			fmt.Fprintf(&b, "%d:\n- %s\n+ %s\n", i+1, w, g)
+		}
+	}
+	return b.String()
+}
+
+// Harness plays a game on a simulation screen, so it can be driven by
+// scripted events and its cell buffer checked without a terminal
+type Harness struct {
+	g    *Game
+	s    tcell.SimulationScreen
+	Done bool // the game has quit
+}
+
+// NewHarness starts g on a w×h simulation screen
+func NewHarness(g *Game, w, h int) (*Harness, error) {
+	s := tcell.NewSimulationScreen("UTF-8")
+	if err := s.Init(); err != nil {
+		return nil, err
+	}
+	s.SetSize(w, h)
+	g.setScreen(s)
+	hs := &Harness{g: g, s: s}
+	g.draw()
+	return hs, nil
+}
+
+// Close finalizes the simulation screen
+func (h *Harness) Close() { h.s.Fini() }
+
+// event handles ev and redraws, as one turn of Run's loop
+func (h *Harness) event(ev tcell.Event) {
+	if h.Done {
+		return
+	}
+	h.Done = h.g.handleEvent(ev)
+	h.g.draw()
+}
+
+// Key presses a special key, or the rune r when k is tcell.KeyRune
+func (h *Harness) Key(k tcell.Key, r rune) {
+	h.event(tcell.NewEventKey(k, r, tcell.ModNone))
+}
+
+// Type presses the runes of text in turn
+func (h *Harness) Type(text string) {
+	for _, r := range text {
+		h.Key(tcell.KeyRune, r)
+	}
+}
+
+// Click presses and releases the left mouse button at cell (x, y)
+func (h *Harness) Click(x, y int) {
+	h.event(tcell.NewEventMouse(x, y, tcell.Button1, tcell.ModNone))
+	h.event(tcell.NewEventMouse(x, y, tcell.ButtonNone, tcell.ModNone))
+}
+
+// Resize changes the screen size as a terminal resize would
+func (h *Harness) Resize(w, ht int) {
+	h.s.SetSize(w, ht)
+	h.event(tcell.NewEventResize(w, ht))
+}
+
+// Snapshot returns the screen's text, one line per row with trailing spaces
+// trimmed. Styles are not included.
+func (h *Harness) Snapshot() string {
+	cells, w, ht := h.s.GetContents()
+	var b strings.Builder
+	for y := range ht {
+		var line strings.Builder
+		for x := 0; x < w; x++ {
+			c := cells[y*w+x]
+			if len(c.Runes) == 0 {
+				line.WriteByte(' ')
+				continue
+			}
+			text := string(c.Runes)
+			line.WriteString(text)
+			// a wide character covers the next cell too
+			if uniseg.StringWidth(text) == 2 {
+				x++
+			}
+		}
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// RunScript plays a script against g and writes the snapshots it asks for
+// to out. Each line of the script is one of:
+//
+//	size <w> <h>       start (first line only) or resize the screen
+//	keys <keys>        type text; <Name> presses a key, e.g. <Enter>, <Esc>, <Right>
+//	click <x> <y>      click the left mouse button
+//	snapshot [name]    write the screen to out under a "== name ==" heading
+//
+// Blank lines and lines starting with # are skipped.
+func RunScript(g *Game, script io.Reader, out io.Writer) error {
+	var h *Harness
+	defer func() {
+		if h != nil {
+			h.Close()
+		}
+	}()
+	sc := bufio.NewScanner(script)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cmd, arg, _ := strings.Cut(line, " ")
+		if h == nil && cmd != "size" {
+			var err error
+			if h, err = NewHarness(g, 80, 24); err != nil {
+				return err
+			}
+		}
+		if h != nil && h.Done {
+			return fmt.Errorf("line %d: the game has already quit", n)
+		}
+		var err error
+		switch cmd {
+		case "size":
+			var w, ht int
+			if w, ht, err = scriptPoint(arg); err != nil {
+				break
+			}
+			if h == nil {
+				h, err = NewHarness(g, w, ht)
+			} else {
+				h.Resize(w, ht)
+			}
+		case "keys":
+			err = scriptKeys(h, arg)
+		case "click":
+			var x, y int
+			if x, y, err = scriptPoint(arg); err == nil {
+				h.Click(x, y)
+			}
+		case "snapshot":
+			fmt.Fprintf(out, "== %s ==\n%s", strings.TrimSpace(arg), h.Snapshot())
+		default:
+			err = fmt.Errorf("unknown command %q", cmd)
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+	return sc.Err()
+}
+
+// scriptPoint parses "x y"
+func scriptPoint(arg string) (int, int, error) {
+	f := strings.Fields(arg)
+	if len(f) != 2 {
+		return 0, 0, fmt.Errorf("expected two numbers, got %q", arg)
+	}
+	x, err := strconv.Atoi(f[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	y, err := strconv.Atoi(f[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	return x, y, nil
+}
+
+// scriptKeys types keys written as text with <Name> for special keys
+func scriptKeys(h *Harness, keys string) error {
+	for keys != "" {
+		if name, rest, ok := strings.Cut(keys[1:], ">"); keys[0] == '<' && ok && name != "" {
+			b, err := parseKey(name)
+			if err != nil {
+				return err
+			}
+			h.Key(b.key, b.r)
+			keys = rest
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(keys)
+		h.Key(tcell.KeyRune, r)
+		keys = keys[size:]
+	}
+	return nil
+}
+
+// loadSampleBoard loads questions/board.csv, a fresh copy for each caller
+func loadSampleBoard(t *testing.T) *Board {
+	t.Helper()
+	b, err := board.Load(filepath.Join("questions", "board.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// testConfig is the default config with images off, so the terminal running
+// the tests can't change the result, and teams if any are given
+func testConfig(teams ...string) *Config {
+	cfg := DefaultConfig()
+	cfg.Images = ImagesNone
+	cfg.Teams = teams
+	return cfg
+}
+
+// testGame is a game with teams Red and Blue running on a simulation screen
+// in its own goroutine, as Run would in a terminal
+type testGame struct {
+	g      *Game
+	host   tcell.SimulationScreen
+	events chan engine.Event // every engine event, sent from the host's loop
+	addr   string            // where a test serves the game, if it does
+	done   chan error        // gets Run's result
+}
+
+// newTestGame sets up a test game; start it with run once anything that
+// must be in place before Run, like a control socket, is
+func newTestGame(t *testing.T) *testGame {
+	t.Helper()
+	g, err := NewGame(loadSampleBoard(t), testConfig("Red", "Blue"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tg := &testGame{g: g, host: tcell.NewSimulationScreen("UTF-8"), events: make(chan engine.Event, 100), done: make(chan error, 1)}
+	g.e.Subscribe(func(ev engine.Event) {
+		select {
+		case tg.events <- ev:
+		default: // the test isn't looking at events
+		}
+	})
+	return tg
+}
+
+// run starts Run, stopping the game when the test ends unless the test
+// already has
+func (tg *testGame) run(t *testing.T) {
+	t.Helper()
+	go func() { tg.done <- tg.g.Run(tg.host) }()
+	t.Cleanup(func() { tg.stop(t) })
+	<-tg.g.running
+}
+
+// stop quits the game and waits for Run to return
+func (tg *testGame) stop(t *testing.T) {
+	t.Helper()
+	select {
+	case <-tg.g.stopped:
+		return
+	default:
+	}
+	tg.host.InjectKey(tcell.KeyEsc, 0, tcell.ModNone) // back to the board
+	tg.host.InjectKey(tcell.KeyCtrlC, 0, tcell.ModNone)
+	if err := <-tg.done; err != nil {
+		t.Error(err)
+	}
+}
+
+// waitEvent waits for the first engine event accepted by match
+func (tg *testGame) waitEvent(t *testing.T, what string, match func(engine.Event) bool) engine.Event {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev := <-tg.events:
+			if match(ev) {
+				return ev
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s", what)
+			return nil
+		}
+	}
+}
+
+// openClue opens the clue under the host's cursor
+func (tg *testGame) openClue(t *testing.T) {
+	t.Helper()
+	tg.host.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	tg.waitEvent(t, "the clue to open", func(ev engine.Event) bool {
+		_, ok := ev.(engine.ClueOpened)
+		return ok
+	})
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// actionHelp describes each action in the help overlay
+var actionHelp = map[string]string{
+	ActionQuit:       "quit (asks first)",
+	ActionLeft:       "move left",
+	ActionRight:      "move right",
+	ActionUp:         "move up",
+	ActionDown:       "move down",
+	ActionOpen:       "open the selected clue",
+	ActionCancel:     "clear the score command",
+	ActionReveal:     "toggle question and answer",
+	ActionBack:       "back to the board",
+	ActionScrollUp:   "scroll the clue up",
+	ActionScrollDown: "scroll the clue down",
+	ActionHelp:       "show or hide this help",
+	ActionTeams:      "rename, add, remove, merge or reorder teams",
+	ActionUndo:       "undo the last score or team change",
+	ActionControl:    "give the next pick to the next team",
+}
+
+// scoreCommandHelp lists the keys that start a score command, on the board
+// and in a clue outside of practice
+var scoreCommandHelp = [][2]string{
+	{"0-9", "type a score command, e.g. 1+200 or 1+,2-"},
+	{":", "type a score command naming teams, e.g. :red=0"},
+}
+
+// phaseExtraHelp lists the fixed keys of each phase, which can't be remapped
+var phaseExtraHelp = map[int][][2]string{
+	PhaseBoard:    {{"Ctrl-C", "quit immediately"}},
+	PhaseQuestion: {{"Ctrl-C", "quit immediately"}},
+}
+
+var phaseNames = map[int]string{
+	PhaseBoard:    "board",
+	PhaseQuestion: "question",
+}
+
+// helpRows returns the key and description rows of the help overlay for the
+// current phase
+func (g *Game) helpRows() [][2]string {
+	var rows [][2]string
+	for _, action := range phaseActions[g.phase] {
+		if g.practice != nil && slices.Contains(practiceOff, action) {
+			continue
+		}
+		names := make([]string, len(g.keys[action]))
+		for i, b := range g.keys[action] {
+			names[i] = b.String()
+		}
+		if len(names) == 0 {
+			names = []string{"(unbound)"}
+		}
+		rows = append(rows, [2]string{strings.Join(names, " "), actionHelp[action]})
+	}
+	if g.practice == nil && (g.phase == PhaseBoard || g.phase == PhaseQuestion) {
+		rows = append(rows, scoreCommandHelp...)
+	}
+	return append(rows, phaseExtraHelp[g.phase]...)
+}
+
+// drawHelp draws the key binding overlay centered over the current screen
+func (g *Game) drawHelp() {
+	s := g.s
+	w, h := s.Size()
+	rows := g.helpRows()
+
+	keyW, descW := 0, 0
+	for _, row := range rows {
+		keyW = max(keyW, textWidth(row[0]))
+		descW = max(descW, textWidth(row[1]))
+	}
+	title := fmt.Sprintf(" keys: %s ", phaseNames[g.phase])
+	footer := " press any key to close "
+	boxW := min(w, max(keyW+descW+7, textWidth(title)+4, textWidth(footer)+4))
+	boxH := min(h, len(rows)+4)
+	x, y := (w-boxW)/2, (h-boxH)/2
+
+	// nothing raw may show through the overlay
+	g.pt.Drop()
+
+	fillBox(s, x, y, boxW, boxH, stylePrompt())
+	drawBox(s, x, y, boxW, boxH, stylePrompt())
+	drawCenteredText(s, x, y, boxW, 1, stylePrompt(), truncateText(title, boxW-2))
+	drawCenteredText(s, x, y+boxH-1, boxW, 1, stylePrompt().Bold(false), truncateText(footer, boxW-2))
+	for i, row := range rows {
+		ry := y + 2 + i
+		if ry >= y+boxH-2 {
+			break
+		}
+		drawText(s, x+2, ry, stylePrompt(), truncateText(row[0], boxW-4))
+		if dx := x + 2 + keyW + 3; dx < x+boxW-2 {
+			drawText(s, dx, ry, stylePrompt().Bold(false), truncateText(row[1], x+boxW-2-dx))
+		}
+	}
+}
+
+// handleHelpKey closes the help overlay on any key but Ctrl-C, which quits
+func (g *Game) handleHelpKey(key tcell.Key) bool {
+	if key == tcell.KeyCtrlC {
+		return true
+	}
+	g.showHelp = false
+	return false
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// tokenClass is the syntax class of a highlighted run of code
+type tokenClass uint8
+
+const (
+	tokPlain tokenClass = iota
+	tokKeyword
+	tokBuiltin
+	tokString
+	tokNumber
+	tokComment
+)
+
+// language describes just enough of a language's lexical syntax to color it
+type language struct {
+	keywords     map[string]bool
+	builtins     map[string]bool
+	lineComment  string
+	blockComment [2]string // start and end, empty if the language has none
+	quotes       string    // characters that open a string literal
+}
+
+func words(s string) map[string]bool {
+	m := map[string]bool{}
+	for _, w := range strings.Fields(s) {
+		m[w] = true
+	}
+	return m
+}
+
+var (
+	langPython = &language{
+		keywords: words(`and as assert async await break class continue def del elif else except
+			False finally for from global if import in is lambda None nonlocal not or pass raise
+			return True try while with yield match case`),
+		builtins: words(`abs all any bool bytes dict enumerate filter float input int isinstance
+			len list map max min object open print range repr reversed set sorted str sum
+			super tuple type zip self`),
+		lineComment: "#",
+		quotes:      `"'`,
+	}
+	langGo = &language{
+		keywords: words(`break case chan const continue default defer else fallthrough for func
+			go goto if import interface map package range return select struct switch type var
+			true false nil iota`),
+		builtins: words(`any append bool byte cap clear close complex copy delete error float32
+			float64 int int8 int16 int32 int64 len make max min new panic print println recover
+			rune string uint uint8 uint16 uint32 uint64 uintptr`),
+		lineComment:  "//",
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'`",
+	}
+	langC = &language{
+		keywords: words(`auto break case const continue default do else enum extern for goto if
+			inline register restrict return sizeof static struct switch typedef union volatile
+			while NULL true false #include #define #ifdef #ifndef #endif #if #else`),
+		builtins: words(`bool char double float int long short signed unsigned void size_t
+			printf scanf malloc calloc free strlen strcpy memcpy`),
+		lineComment:  "//",
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"'`,
+	}
+)
+
+// languages maps code fence tags to their syntax
+var languages = map[string]*language{
+	"python": langPython,
+	"py":     langPython,
+	"go":     langGo,
+	"golang": langGo,
+	"c":      langC,
+	"h":      langC,
+}
+
+// lookupLanguage returns the syntax for a fence tag, or nil for plain code
+func lookupLanguage(tag string) *language {
+	return languages[strings.ToLower(tag)]
+}
+
+// highlightLine splits one line of code into token spans. inBlock carries an
+// open block comment from one line to the next.
+func highlightLine(lang *language, line string, inBlock *bool) []span {
+	var spans []span
+	emit := func(text string, tok tokenClass) {
+		if text != "" {
+			spans = appendSpan(spans, span{text: text, attr: spanCode, tok: tok})
+		}
+	}
+
+	rest := line
+	for rest != "" {
+		if *inBlock {
+			end := strings.Index(rest, lang.blockComment[1])
+			if end < 0 {
+				emit(rest, tokComment)
+				return spans
+			}
+			end += len(lang.blockComment[1])
+			emit(rest[:end], tokComment)
+			rest = rest[end:]
+			*inBlock = false
+			continue
+		}
+
+		switch r, _ := utf8.DecodeRuneInString(rest); {
+		case lang.lineComment != "" && strings.HasPrefix(rest, lang.lineComment):
+			emit(rest, tokComment)
+			return spans
+		case lang.blockComment[0] != "" && strings.HasPrefix(rest, lang.blockComment[0]):
+			*inBlock = true
+			emit(lang.blockComment[0], tokComment)
+			rest = rest[len(lang.blockComment[0]):]
+		case strings.ContainsRune(lang.quotes, r):
+			n := scanString(rest, r)
+			emit(rest[:n], tokString)
+			rest = rest[n:]
+		case unicode.IsDigit(r):
+			n := scanWord(rest, true)
+			emit(rest[:n], tokNumber)
+			rest = rest[n:]
+		case unicode.IsLetter(r) || r == '_' || r == '#':
+			n := 1 + scanWord(rest[1:], false)
+			word := rest[:n]
+			switch {
+			case lang.keywords[word]:
+				emit(word, tokKeyword)
+			case lang.builtins[word]:
+				emit(word, tokBuiltin)
+			default:
+				emit(word, tokPlain)
+			}
+			rest = rest[n:]
+		default:
+			n := len(string(r))
+			emit(rest[:n], tokPlain)
+			rest = rest[n:]
+		}
+	}
+	return spans
+}
+
+// scanString returns the length of the string literal at the start of s,
+// up to and including the closing quote or the end of the line
+func scanString(s string, quote rune) int {
+	escaped := false
+	for i, r := range s {
+		if i == 0 {
+			continue
+		}
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '`':
+			escaped = true
+		case r == quote:
+			return i + len(string(r))
+		}
+	}
+	return len(s)
+}
+
+// scanWord returns the length of the identifier or number at the start of s.
+// Numbers may contain dots, identifiers stop at them.
+func scanWord(s string, number bool) int {
+	for i, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && (r != '.' || !number) {
+			return i
+		}
+	}
+	return len(s)
+}
@@ -15,10 +15,14 @@ import (
 )
 
 // handle image rendering in terminal
-type ImageRenderer struct{}
+type ImageRenderer struct {
+	// encoded images keyed by path and size, so redraws don't re-encode
+	cache  map[string]string
+	bounds map[string]image.Point
+}
 
 func NewImageRenderer() *ImageRenderer {
-	return &ImageRenderer{}
+	return &ImageRenderer{cache: map[string]string{}, bounds: map[string]image.Point{}}
 }
 
 func (ir *ImageRenderer) RenderImageToString(imagePath string, maxWidth, maxHeight int) (string, error) {
@@ -26,6 +30,11 @@ func (ir *ImageRenderer) RenderImageToString(imagePath string, maxWidth, maxHeig
 		return "", nil
 	}
 
+	key := fmt.Sprintf("%s@%dx%d", imagePath, maxWidth, maxHeight)
+	if data, ok := ir.cache[key]; ok {
+		return data, nil
+	}
+
 	if _, err := os.Stat(imagePath); os.IsNotExist(err) {
 		return "", fmt.Errorf("image file not found: %s", imagePath)
 	}
@@ -57,7 +66,8 @@ func (ir *ImageRenderer) RenderImageToString(imagePath string, maxWidth, maxHeig
 		return "", fmt.Errorf("failed to encode image for terminal: %w", err)
 	}
 
-	return buf.String(), nil
+	ir.cache[key] = buf.String()
+	return ir.cache[key], nil
 }
 
 // GetImageBounds returns the original image dimensions in pixels
@@ -66,6 +76,10 @@ func (ir *ImageRenderer) GetImageBounds(imagePath string) (width, height int, er
 		return 0, 0, fmt.Errorf("empty image path")
 	}
 
+	if p, ok := ir.bounds[imagePath]; ok {
+		return p.X, p.Y, nil
+	}
+
 	if _, err := os.Stat(imagePath); os.IsNotExist(err) {
 		return 0, 0, fmt.Errorf("image file not found: %s", imagePath)
 	}
@@ -82,6 +96,7 @@ func (ir *ImageRenderer) GetImageBounds(imagePath string) (width, height int, er
 	}
 
 	bounds := img.Bounds()
+	ir.bounds[imagePath] = image.Pt(bounds.Dx(), bounds.Dy())
 	return bounds.Dx(), bounds.Dy(), nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/maristcomputersociety/tuipardy/engine"
 )
 
 func (g *Game) handleMouse(ev *tcell.EventMouse) {
@@ -21,26 +22,30 @@ func (g *Game) handleMouse(ev *tcell.EventMouse) {
 	}
 	now := time.Now()
 	if g.lastCell == [2]int{col, row} && now.Sub(g.lastClick) < 350*time.Millisecond {
-		g.cursorCol, g.cursorRow = col, row
-		g.openSelected()
+		if g.do(engine.Select{Col: col, Row: row}) {
+			g.do(engine.Open{})
+		}
 		g.lastClick = time.Time{}
 		return
 	}
-	g.cursorCol, g.cursorRow = col, row
+	g.do(engine.Select{Col: col, Row: row})
 	g.lastCell = [2]int{col, row}
 	g.lastClick = now
 }
 
 func (g *Game) hitTestCell(x, y int) (c, r int, ok bool) {
-	w, _ := g.s.Size()
-	cols := len(g.board.Categories)
-	colW := max(MinColumnWidth, w/cols)
-	if y < CategoryHeight || y >= CategoryHeight+QuestionsPerCategory*CellHeight {
+	l := g.layoutBoard(g.s.Size())
+	if l.tooSmall() || y < l.cellY(0) || y >= l.cellY(l.rows) {
 		return 0, 0, false
 	}
-	r = (y - CategoryHeight) / CellHeight
-	c = x / colW
-	if c < 0 || c >= cols || r < 0 || r >= QuestionsPerCategory {
+	r = (y - l.cellY(0)) / l.cellH
+	c = -1
+	for i := range l.cols {
+		if x >= l.colX(i) {
+			c = i
+		}
+	}
+	if c < 0 || c >= l.cols || r < 0 || r >= l.rows {
 		return 0, 0, false
 	}
 	return c, r, true
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// actions that can be bound to keys
+const (
+	ActionQuit       = "quit"
+	ActionLeft       = "left"
+	ActionRight      = "right"
+	ActionUp         = "up"
+	ActionDown       = "down"
+	ActionOpen       = "open"
+	ActionCancel     = "cancel" // clear a half typed score command
+	ActionReveal     = "reveal"
+	ActionBack       = "back"
+	ActionScrollUp   = "scroll-up"
+	ActionScrollDown = "scroll-down"
+	ActionHelp       = "help"
+	ActionTeams      = "teams"
+	ActionUndo       = "undo"
+	ActionControl    = "control" // give the next pick to the following team
+)
+
+// phaseActions lists the actions available in each phase, in the order
+// they are looked up
+var phaseActions = map[int][]string{
+	PhaseBoard:    {ActionQuit, ActionLeft, ActionRight, ActionUp, ActionDown, ActionOpen, ActionCancel, ActionTeams, ActionUndo, ActionControl, ActionHelp},
+	PhaseQuestion: {ActionReveal, ActionBack, ActionScrollUp, ActionScrollDown, ActionHelp},
+}
+
+// defaultKeys are the bindings used for actions the config doesn't mention
+var defaultKeys = map[string][]string{
+	ActionQuit:       {"q", "Q"},
+	ActionLeft:       {"Left", "h"},
+	ActionRight:      {"Right", "l"},
+	ActionUp:         {"Up", "k"},
+	ActionDown:       {"Down", "j"},
+	ActionOpen:       {"Enter"},
+	ActionCancel:     {"Esc"},
+	ActionReveal:     {"Space", "Enter"},
+	ActionBack:       {"Esc"},
+	ActionScrollUp:   {"PgUp"},
+	ActionScrollDown: {"PgDn"},
+	ActionHelp:       {"?"},
+	ActionTeams:      {"t"},
+	ActionUndo:       {"u"},
+	ActionControl:    {"c"},
+}
+
+// keyBinding is a single key, either a special key or a rune
+type keyBinding struct {
+	key tcell.Key
+	r   rune
+}
+
+// Keymap maps actions to the keys bound to them
+type Keymap map[string][]keyBinding
+
+// NewKeymap builds a keymap from the defaults, replacing the bindings of any
+// action present in overrides
+func NewKeymap(overrides map[string][]string) (Keymap, error) {
+	km := Keymap{}
+	for action, names := range defaultKeys {
+		if _, ok := overrides[action]; ok {
+			continue
+		}
+		if err := km.bind(action, names); err != nil {
+			return nil, err
+		}
+	}
+	for action, names := range overrides {
+		if _, ok := defaultKeys[action]; !ok {
+			return nil, fmt.Errorf("unknown action %q", action)
+		}
+		if err := km.bind(action, names); err != nil {
+			return nil, err
+		}
+	}
+	return km, nil
+}
+
+func (km Keymap) bind(action string, names []string) error {
+	for _, name := range names {
+		b, err := parseKey(name)
+		if err != nil {
+			return fmt.Errorf("action %q: %w", action, err)
+		}
+		km[action] = append(km[action], b)
+	}
+	return nil
+}
+
+// Action returns the action a key triggers in the given phase, or "" if none
+func (km Keymap) Action(phase int, key tcell.Key, r rune) string {
+	for _, action := range phaseActions[phase] {
+		for _, b := range km[action] {
+			if b.key == key && (key != tcell.KeyRune || b.r == r) {
+				return action
+			}
+		}
+	}
+	return ""
+}
+
+// Names lists the keys bound to an action for use in hints, e.g. "space/enter"
+func (km Keymap) Names(action string) string {
+	names := make([]string, len(km[action]))
+	for i, b := range km[action] {
+		names[i] = strings.ToLower(b.String())
+	}
+	return strings.Join(names, "/")
+}
+
+// keysByName maps lower cased tcell key names ("enter", "ctrl-c") to keys
+var keysByName = func() map[string]tcell.Key {
+	m := map[string]tcell.Key{}
+	for k, name := range tcell.KeyNames {
+		m[strings.ToLower(name)] = k
+	}
+	// tcell reports Enter as its own key rather than Ctrl-M
+	m["enter"] = tcell.KeyEnter
+	m["return"] = tcell.KeyEnter
+	m["escape"] = tcell.KeyEsc
+	return m
+}()
+
+// parseKey parses a key name as written in config files: a single character
+// ("h", "?"), "Space", or a tcell key name such as "Enter", "PgDn" or "Ctrl-C"
+func parseKey(name string) (keyBinding, error) {
+	if utf8.RuneCountInString(name) == 1 {
+		r, _ := utf8.DecodeRuneInString(name)
+		return keyBinding{key: tcell.KeyRune, r: r}, nil
+	}
+	if strings.EqualFold(name, "space") {
+		return keyBinding{key: tcell.KeyRune, r: ' '}, nil
+	}
+	if k, ok := keysByName[strings.ToLower(name)]; ok {
+		return keyBinding{key: k}, nil
+	}
+	return keyBinding{}, fmt.Errorf("unknown key %q", name)
+}
+
+// String returns the key's name as shown to the user
+func (b keyBinding) String() string {
+	switch {
+	case b.key == tcell.KeyRune && b.r == ' ':
+		return "Space"
+	case b.key == tcell.KeyRune:
+		return string(b.r)
+	case b.key == tcell.KeyEnter:
+		return "Enter"
+	}
+	if name, ok := tcell.KeyNames[b.key]; ok {
+		return name
+	}
+	return fmt.Sprintf("Key(%d)", b.key)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/maristcomputersociety/tuipardy/board"
+)
+
+// boardLayout is the board geometry for the current screen size. Columns
+// share the screen width evenly, header and cell heights grow with the
+// terminal, and the team list sits below the last row of cells.
+type boardLayout struct {
+	w, h       int
+	cols, rows int
+	headers    [][]string // wrapped category names, one entry per column
+	headerH    int
+	cellH      int
+	teamsY     int // top of the team score cards
+	cardH      int
+	minW, minH int
+}
+
+// layoutBoard computes the board geometry for a w×h screen
+func (g *Game) layoutBoard(w, h int) boardLayout {
+	l := boardLayout{w: w, h: h, cols: len(g.e.Board().Categories), rows: board.QuestionsPerCategory}
+
+	l.minW = l.cols * MinColumnWidth
+	l.minH = MinCategoryHeight + l.rows*MinCellHeight + TeamBaselineOffset + TeamCardHeight + StatusBarHeight
+
+	// big score cards only when the board values can be big as well
+	l.cardH = TeamCardHeight
+	bigBoardH := MinCategoryHeight + l.rows*(BigFontHeight+2)
+	if h-StatusBarHeight-TeamBaselineOffset-BigTeamCardHeight >= bigBoardH {
+		l.cardH = BigTeamCardHeight
+	}
+	teamPanelH := TeamBaselineOffset + l.cardH
+
+	// wrap category names to fit inside the header borders
+	lines := 1
+	l.headers = make([][]string, l.cols)
+	for c, cat := range g.e.Board().Categories {
+		l.headers[c] = wrapHeader(strings.ToUpper(cat.Name), l.colWidth(c)-2)
+		lines = max(lines, len(l.headers[c]))
+	}
+	l.headerH = lines + 2
+
+	// headers give back lines before cells shrink below their minimum
+	avail := h - teamPanelH - StatusBarHeight
+	for l.headerH > MinCategoryHeight && avail-l.headerH < l.rows*MinCellHeight {
+		l.headerH--
+	}
+	l.cellH = max(MinCellHeight, (avail-l.headerH)/l.rows)
+	l.teamsY = l.cellY(l.rows) + TeamBaselineOffset
+	return l
+}
+
+// tooSmall reports whether the board can't be drawn legibly
+func (l boardLayout) tooSmall() bool { return l.w < l.minW || l.h < l.minH }
+
+// colX returns the left edge of column c
+func (l boardLayout) colX(c int) int { return c * l.w / l.cols }
+
+// colWidth returns the width of column c, spreading leftover columns evenly
+func (l boardLayout) colWidth(c int) int { return l.colX(c+1) - l.colX(c) }
+
+// cellY returns the top edge of question row r
+func (l boardLayout) cellY(r int) int { return l.headerH + r*l.cellH }
+
+// wrapHeader wraps a category name onto at most MaxCategoryLines lines,
+// marking the last line with an ellipsis if the name was cut short
+func wrapHeader(name string, width int) []string {
+	lines := wrapText(name, width)
+	if len(lines) == 0 {
+		return []string{""}
+	}
+	for i, line := range lines {
+		lines[i] = truncateText(line, width)
+	}
+	if len(lines) > MaxCategoryLines {
+		lines = lines[:MaxCategoryLines]
+		last := lines[MaxCategoryLines-1]
+		if textWidth(last) >= width {
+			last, _, _ = cutText(last, max(1, width-1))
+		}
+		lines[MaxCategoryLines-1] = last + "…"
+	}
+	return lines
+}
@@ -3,12 +3,49 @@ package main
 import (
 	"flag"
 	"fmt"
+	"log"
+	"net"
+	"net/http"
 	"os"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/maristcomputersociety/tuipardy/board"
+	"github.com/maristcomputersociety/tuipardy/engine"
+	"github.com/maristcomputersociety/tuipardy/spectator"
 )
 
 func main() {
+	// "tuipardy practice <board.csv>" plays alone, taking the same flags
+	if len(os.Args) > 1 && os.Args[1] == "study" {
+		os.Exit(studyMain(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "generate" {
+		os.Exit(generateMain(os.Args[2:]))
+	}
+	practice := len(os.Args) > 1 && os.Args[1] == "practice"
+	if practice {
+		os.Args = append(os.Args[:1:1], os.Args[2:]...)
+	}
+
+	configPath := flag.String("config", "", "extra config file, applied over the user and board config files")
+	teams := flag.String("teams", "", "comma separated team names, skipping team setup")
+	minTeams := flag.Int("min-teams", engine.MinTeams, "fewest teams allowed")
+	maxTeams := flag.Int("max-teams", engine.MaxTeams, "most teams allowed")
+	images := flag.String("images", ImagesAuto, "image support: auto, kitty or none")
+	clueTimer := flag.Duration("clue-timer", 0, "countdown shown on each clue, e.g. 30s (0 for none)")
+	themeName := flag.String("theme", "classic", "color theme: "+strings.Join(ThemeNames(), ", ")+", or a TOML/JSON theme file")
+	serve := flag.String("serve", "", "serve a read-only spectator view for browsers on this address, e.g. :8080")
+	sshAddr := flag.String("ssh", "", "let players join over SSH on this address, e.g. :2222")
+	sshHostKey := flag.String("ssh-host-key", SSHHostKeyPath(), "SSH host key file, generated if missing")
+	sshKeys := flag.String("ssh-keys", "", "authorized_keys file of players allowed in over SSH; without it anyone can watch but no one can take a team seat")
+	overlayDir := flag.String("overlay-dir", "", "keep scores.txt, current_clue.txt and state.json in this directory up to date for streaming overlays")
+	overlayTemplates := flag.String("overlay-templates", "", "directory of <file>.tmpl text/template files for --overlay-dir, replacing or adding output files")
+	controlPath := flag.String("control", "", "take JSON commands from scripts on a Unix socket at this path")
+	narrate := flag.String("narrate", "", "write a plain text line for every state change to this file or FIFO")
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "usage: %s <board.csv>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "usage: %s [practice] [flags] <board.csv>\n       %s study [flags] <board.csv>...\n       %s generate [flags] <bank>\n", os.Args[0], os.Args[0], os.Args[0])
+		flag.PrintDefaults()
 	}
 	flag.Parse()
 	if flag.NArg() < 1 {
@@ -17,15 +54,173 @@ func main() {
 	}
 	csvPath := flag.Arg(0)
 
-	board, err := LoadBoard(csvPath)
+	cfg, err := LoadConfig(UserConfigPath(), BoardConfigPath(csvPath), *configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	themeChosen := cfg.themeSet
+	// flags override the config files, but only when given
+	if flagSet("teams") {
+		cfg.Teams = nil
+		for _, name := range strings.Split(*teams, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				cfg.Teams = append(cfg.Teams, name)
+			}
+		}
+	}
+	if flagSet("min-teams") {
+		cfg.MinTeams = *minTeams
+	}
+	if flagSet("max-teams") {
+		cfg.MaxTeams = *maxTeams
+	}
+	if flagSet("images") {
+		cfg.Images = *images
+	}
+	if flagSet("clue-timer") {
+		cfg.Timers.Clue.Duration = *clueTimer
+	}
+	if flagSet("theme") {
+		cfg.Theme = *themeName
+		themeChosen = true
+	}
+	if err := cfg.validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "error in settings: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := applyTheme(cfg, themeChosen); err != nil {
+		fmt.Fprintf(os.Stderr, "error loading theme: %v\n", err)
+		os.Exit(1)
+	}
+
+	b, err := board.Load(csvPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error loading board: %v\n", err)
 		os.Exit(1)
 	}
 
-	g := NewGame(board)
-	if err := g.Run(); err != nil {
+	var g *Game
+	if practice {
+		player := "You"
+		if len(cfg.Teams) > 0 {
+			player = cfg.Teams[0]
+		}
+		g, err = NewPracticeGame(b, cfg, player)
+	} else {
+		g, err = NewGame(b, cfg)
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
 		os.Exit(1)
 	}
+	if *narrate != "" {
+		n := NewNarrator(*narrate)
+		defer n.Close()
+		g.setNarrator(n)
+	}
+	if *overlayDir != "" {
+		o, err := NewOverlay(*overlayDir, *overlayTemplates)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error starting overlay: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			// failures not shown during the game are reported once it's over
+			o.Close()
+			if err := o.NewErrors(); err != nil {
+				fmt.Fprintf(os.Stderr, "overlay: %v\n", err)
+			}
+		}()
+		o.Update(g.e)
+		g.e.Subscribe(func(engine.Event) {
+			o.Update(g.e)
+			if err := o.NewErrors(); err != nil {
+				g.flashMsg("overlay: %v", err)
+			}
+		})
+	}
+	if *serve != "" {
+		if err := startSpectators(g, *serve); err != nil {
+			fmt.Fprintf(os.Stderr, "error starting spectator view: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *sshAddr != "" {
+		if err := startSSH(g, *sshAddr, *sshHostKey, *sshKeys); err != nil {
+			fmt.Fprintf(os.Stderr, "error starting SSH server: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *controlPath != "" {
+		ln, err := startControl(g, *controlPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error starting control socket: %v\n", err)
+			os.Exit(1)
+		}
+		defer ln.Close()
+	}
+
+	s, err := tcell.NewScreen()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		os.Exit(1)
+	}
+	if err := g.Run(s); err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		os.Exit(1)
+	}
+	if practice {
+		// keep the summary around once the screen is gone
+		fmt.Println(strings.Join(g.practiceSummary(), "\n"))
+	}
+}
+
+// applyTheme loads and uses the configured theme. NO_COLOR
+// (https://no-color.org) applies unless a theme was asked for.
+func applyTheme(cfg *Config, chosen bool) error {
+	if os.Getenv("NO_COLOR") != "" && !chosen {
+		cfg.Theme = "monochrome"
+	}
+	t, err := LoadTheme(cfg.Theme)
+	if err != nil {
+		return err
+	}
+	SetTheme(t)
+	return nil
+}
+
+// startSpectators serves the spectator view on addr, following the game's
+// events. Only binding addr can fail here; later server errors are logged.
+func startSpectators(g *Game, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := spectator.New()
+	srv.Format = plainClue
+	srv.Update(g.e)
+	g.e.Subscribe(func(engine.Event) { srv.Update(g.e) })
+	go func() {
+		// Serve only returns when the listener fails, which the host should
+		// hear about even if it garbles the screen
+		if err := http.Serve(ln, srv.Handler()); err != nil {
+			log.Printf("spectator view stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+// flagSet reports whether the named flag was given on the command line
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
 }
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Clue text supports a small markup:
+//
+//	line breaks in the CSV field are kept
+//	```lang ... ``` fences a code block, shown monospaced with indentation kept
+//	**bold**, *italic* and `code` spans inside ordinary lines
+//	a backslash escapes the next markup character
+
+type spanAttr uint8
+
+const (
+	spanBold spanAttr = 1 << iota
+	spanItalic
+	spanCode
+)
+
+// span is a run of text sharing the same attributes
+type span struct {
+	text string
+	attr spanAttr
+	tok  tokenClass // syntax class inside highlighted code
+}
+
+// clueBlock is either a run of prose lines or a fenced code block
+type clueBlock struct {
+	code  bool
+	lang  string // fence tag, e.g. "go"
+	lines []string
+}
+
+// clueLine is one laid out line of a clue
+type clueLine struct {
+	spans []span
+	width int
+	block int // index of the code block the line belongs to, -1 for prose
+}
+
+const codeTabWidth = 4
+
+// parseClue splits clue text into prose and code blocks
+func parseClue(text string) []clueBlock {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	var blocks []clueBlock
+	cur := -1 // index of the block being filled, -1 after a closing fence
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			if cur >= 0 && blocks[cur].code {
+				cur = -1
+				continue
+			}
+			blocks = append(blocks, clueBlock{code: true, lang: strings.TrimSpace(trimmed[3:])})
+			cur = len(blocks) - 1
+			continue
+		}
+		if cur < 0 {
+			blocks = append(blocks, clueBlock{})
+			cur = len(blocks) - 1
+		}
+		blocks[cur].lines = append(blocks[cur].lines, line)
+	}
+	return blocks
+}
+
+// parseSpans splits a prose line into styled spans
+func parseSpans(line string) []span {
+	var spans []span
+	var buf strings.Builder
+	var attr spanAttr
+	flush := func() {
+		if buf.Len() > 0 {
+			spans = append(spans, span{text: buf.String(), attr: attr})
+			buf.Reset()
+		}
+	}
+
+	rs := []rune(line)
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case r == '\\' && i+1 < len(rs) && strings.ContainsRune("\\*`", rs[i+1]):
+			i++
+			buf.WriteRune(rs[i])
+		case r == '`':
+			end := indexRune(rs, '`', i+1)
+			if end < 0 {
+				buf.WriteRune(r)
+				continue
+			}
+			flush()
+			spans = append(spans, span{text: string(rs[i+1 : end]), attr: attr | spanCode})
+			i = end
+		case r == '*' && i+1 < len(rs) && rs[i+1] == '*':
+			if attr&spanBold == 0 && !opensSpan(rs, i+2, "**") {
+				buf.WriteString("**")
+				i++
+				continue
+			}
+			flush()
+			attr ^= spanBold
+			i++
+		case r == '*':
+			if attr&spanItalic == 0 && !opensSpan(rs, i+1, "*") {
+				buf.WriteRune(r)
+				continue
+			}
+			flush()
+			attr ^= spanItalic
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	flush()
+	return spans
+}
+
+// opensSpan reports whether the marker ending just before rs[i] starts a
+// span: it must not sit inside a word (so 2*3 stays as is), must be followed
+// by text, and must be closed by an unescaped marker later on the line
+func opensSpan(rs []rune, i int, marker string) bool {
+	if i >= len(rs) || unicode.IsSpace(rs[i]) {
+		return false
+	}
+	if start := i - len(marker); start > 0 {
+		if prev := rs[start-1]; unicode.IsLetter(prev) || unicode.IsDigit(prev) {
+			return false
+		}
+	}
+	m := []rune(marker)
+	for j := i + 1; j+len(m) <= len(rs); j++ {
+		if rs[j-1] == '\\' {
+			continue
+		}
+		if string(rs[j:j+len(m)]) == marker {
+			return true
+		}
+	}
+	return false
+}
+
+func indexRune(rs []rune, r rune, from int) int {
+	for i := from; i < len(rs); i++ {
+		if rs[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// layoutClue parses clue text and wraps it into lines no wider than maxWidth.
+// Prose is word wrapped; code lines are kept as-is and only cut when too long.
+func layoutClue(text string, maxWidth int) []clueLine {
+	maxWidth = max(1, maxWidth)
+	var lines []clueLine
+	for b, block := range parseClue(text) {
+		if block.code {
+			lang := lookupLanguage(block.lang)
+			inComment := false
+			for _, line := range block.lines {
+				line = strings.ReplaceAll(line, "\t", strings.Repeat(" ", codeTabWidth))
+				spans := []span{{text: line, attr: spanCode}}
+				if lang != nil {
+					spans = highlightLine(lang, line, &inComment)
+				}
+				for _, part := range cutSpans(spans, maxWidth) {
+					lines = append(lines, clueLine{spans: part, width: spansWidth(part), block: b})
+				}
+			}
+			continue
+		}
+		for _, line := range block.lines {
+			lines = append(lines, wrapSpans(parseSpans(line), maxWidth)...)
+		}
+	}
+	return lines
+}
+
+// cutSpans splits a line of spans into pieces at most maxWidth cells wide
+func cutSpans(spans []span, maxWidth int) [][]span {
+	var parts [][]span
+	var cur []span
+	width := 0
+	for _, sp := range spans {
+		rest := sp.text
+		for rest != "" {
+			if width >= maxWidth {
+				parts = append(parts, cur)
+				cur, width = nil, 0
+			}
+			head, tail, w := cutText(rest, maxWidth-width)
+			cur = append(cur, span{text: head, attr: sp.attr, tok: sp.tok})
+			width += w
+			rest = tail
+		}
+	}
+	return append(parts, cur)
+}
+
+// wrapSpans word wraps a prose line, keeping each word's attributes
+func wrapSpans(spans []span, maxWidth int) []clueLine {
+	// split into words, each word being one or more spans
+	var words [][]span
+	var word []span
+	for _, sp := range spans {
+		var buf strings.Builder
+		for _, r := range sp.text {
+			if unicode.IsSpace(r) && sp.attr&spanCode == 0 {
+				if buf.Len() > 0 {
+					word = append(word, span{text: buf.String(), attr: sp.attr})
+					buf.Reset()
+				}
+				if len(word) > 0 {
+					words = append(words, word)
+					word = nil
+				}
+				continue
+			}
+			buf.WriteRune(r)
+		}
+		if buf.Len() > 0 {
+			word = append(word, span{text: buf.String(), attr: sp.attr})
+		}
+	}
+	if len(word) > 0 {
+		words = append(words, word)
+	}
+
+	lines := []clueLine{}
+	cur := clueLine{block: -1}
+	for _, w := range words {
+		ww := spansWidth(w)
+		if ww > maxWidth {
+			// too wide for any line, e.g. a long identifier in code: hard
+			// break it, starting on a line of its own
+			if cur.width > 0 {
+				lines = append(lines, cur)
+			}
+			pieces := cutSpans(w, maxWidth)
+			for _, p := range pieces[:len(pieces)-1] {
+				lines = append(lines, clueLine{spans: p, width: spansWidth(p), block: -1})
+			}
+			last := pieces[len(pieces)-1]
+			cur = clueLine{spans: last, width: spansWidth(last), block: -1}
+			continue
+		}
+		if cur.width > 0 && cur.width+1+ww > maxWidth {
+			lines = append(lines, cur)
+			cur = clueLine{block: -1}
+		}
+		if cur.width > 0 {
+			cur.spans = appendSpan(cur.spans, span{text: " "})
+			cur.width++
+		}
+		for _, sp := range w {
+			cur.spans = appendSpan(cur.spans, sp)
+		}
+		cur.width += ww
+	}
+	return append(lines, cur)
+}
+
+// appendSpan appends sp, merging it into the last span when attributes match
+func appendSpan(spans []span, sp span) []span {
+	if n := len(spans); n > 0 && spans[n-1].attr == sp.attr && spans[n-1].tok == sp.tok {
+		spans[n-1].text += sp.text
+		return spans
+	}
+	return append(spans, sp)
+}
+
+func spansWidth(spans []span) int {
+	w := 0
+	for _, sp := range spans {
+		w += textWidth(sp.text)
+	}
+	return w
+}
+
+// lineOffsets returns the x offset of each line centered in width w. Code
+// blocks are centered as a whole so their indentation lines up.
+func lineOffsets(lines []clueLine, w int) []int {
+	blockW := map[int]int{}
+	for _, l := range lines {
+		if l.block >= 0 {
+			blockW[l.block] = max(blockW[l.block], l.width)
+		}
+	}
+	offs := make([]int, len(lines))
+	for i, l := range lines {
+		lw := l.width
+		if l.block >= 0 {
+			lw = blockW[l.block]
+		}
+		offs[i] = max(0, (w-lw)/2)
+	}
+	return offs
+}
+
+// clueText flattens clue markup into plain text on a single line, for
+// narration and other text-only outputs
+func clueText(text string) string {
+	var parts []string
+	for _, line := range plainLines(text) {
+		if line = strings.TrimSpace(line); line != "" {
+			parts = append(parts, line)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// plainClue returns clue text without markup, keeping its line breaks and the
+// indentation of code blocks
+func plainClue(text string) string {
+	return strings.Join(plainLines(text), "\n")
+}
+
+// plainLines returns the lines of a clue with inline markup removed
+func plainLines(text string) []string {
+	var lines []string
+	for _, block := range parseClue(text) {
+		for _, line := range block.lines {
+			if !block.code {
+				var b strings.Builder
+				for _, sp := range parseSpans(line) {
+					b.WriteString(sp.text)
+				}
+				line = b.String()
+			}
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
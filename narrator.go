@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// narratorBacklog is how many lines may queue up before new ones are dropped
+const narratorBacklog = 64
+
+// Narrator writes a plain text line for every game state change to a file
+// or FIFO, so a screen reader or TTS tool can follow along. Writes happen on
+// their own goroutine and never block the UI: opening a FIFO waits for a
+// reader, and lines are dropped if the reader falls too far behind.
+type Narrator struct {
+	lines chan string
+	done  chan struct{}
+}
+
+func NewNarrator(path string) *Narrator {
+	n := &Narrator{
+		lines: make(chan string, narratorBacklog),
+		done:  make(chan struct{}),
+	}
+	go n.run(path)
+	return n
+}
+
+func (n *Narrator) run(path string) {
+	defer close(n.done)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		for range n.lines {
+		}
+		return
+	}
+	defer f.Close()
+	for line := range n.lines {
+		fmt.Fprintln(f, line)
+	}
+}
+
+// Say queues a line of narration. It is a no-op on a nil Narrator.
+func (n *Narrator) Say(format string, args ...any) {
+	if n == nil {
+		return
+	}
+	select {
+	case n.lines <- fmt.Sprintf(format, args...):
+	default:
+	}
+}
+
+// Close flushes queued lines. A FIFO with no reader would block the writer
+// for good, so it waits half a second at most.
+func (n *Narrator) Close() {
+	if n == nil {
+		return
+	}
+	close(n.lines)
+	select {
+	case <-n.done:
+	case <-time.After(500 * time.Millisecond):
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNarratorStart(t *testing.T) {
+	b := loadSampleBoard(t)
+	first := b.Categories[0].Questions[0]
+	tests := []struct {
+		name  string
+		teams []string
+		want  []string
+	}{
+		{"set up on screen", nil, []string{"enter number of teams (2-16): "}},
+		{"configured teams", []string{"Red", "Blue"}, []string{
+			"Game started. Scores: Red 0, Blue 0.",
+			first.Category + ", 100",
+		}},
+	}
+	for _, tt := range tests {
+		g, err := NewGame(b, testConfig(tt.teams...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(t.TempDir(), "narration.txt")
+		n := NewNarrator(path)
+		g.setNarrator(n)
+		n.Close()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: narrated %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/maristcomputersociety/tuipardy/engine"
+	"github.com/maristcomputersociety/tuipardy/spectator"
+)
+
+// default overlay files, rendered from spectator.State
+var overlayTemplates = map[string]string{
+	"scores.txt": `{{range .Teams}}{{.Name}}: {{.Score}}{{if .Control}} ◀{{end}}
+{{end}}`,
+	"current_clue.txt": `{{with .Clue}}{{.Category}} for ${{.Value}}
+{{.Question}}
+{{if .Revealed}}Answer: {{.Answer}}
+{{end}}{{end}}`,
+}
+
+// Overlay keeps a directory of small files describing the game up to date
+// for streaming software, e.g. OBS text sources. Every file is replaced
+// atomically so a reader never sees half of one. Files are rendered on the
+// game's goroutine and written on their own, so a slow disk doesn't hold up
+// the UI; only the newest version of each file is written. Failures are
+// kept for the game to show, see NewErrors.
+type Overlay struct {
+	dir       string
+	templates map[string]*template.Template // output file name -> template
+	last      map[string][]byte             // what each file holds, to skip rewrites; only used by run
+	pending   chan map[string][]byte
+	done      chan struct{}
+
+	mu       sync.Mutex
+	errs     []error         // every distinct failure, oldest first
+	failed   map[string]bool // what has failed, so each failure is kept once
+	reported int             // how many of errs NewErrors has returned
+}
+
+// NewOverlay writes overlay files to dir. state.json always holds the state
+// as JSON; scores.txt and current_clue.txt have built-in formats. Each
+// <name>.tmpl file in templateDir (if not empty) is a text/template rendered
+// to <name>, replacing the built-in format or adding a file. Templates are
+// given a spectator.State.
+func NewOverlay(dir, templateDir string) (*Overlay, error) {
+	o := &Overlay{
+		dir:       dir,
+		templates: map[string]*template.Template{},
+		last:      map[string][]byte{},
+		failed:    map[string]bool{},
+		pending:   make(chan map[string][]byte, 1),
+		done:      make(chan struct{}),
+	}
+	for name, text := range overlayTemplates {
+		o.templates[name] = template.Must(template.New(name).Parse(text))
+	}
+	if templateDir != "" {
+		paths, err := filepath.Glob(filepath.Join(templateDir, "*.tmpl"))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			t, err := template.ParseFiles(path)
+			if err != nil {
+				return nil, err
+			}
+			o.templates[strings.TrimSuffix(filepath.Base(path), ".tmpl")] = t
+		}
+	}
+	// catch templates that can't render before the game starts
+	for _, st := range []spectator.State{{}, overlaySample} {
+		if _, err := o.render(st); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	go o.run()
+	return o, nil
+}
+
+// overlaySample is a state with every field set, for checking templates
+var overlaySample = spectator.State{
+	Phase:      engine.PhaseQuestion.String(),
+	Categories: []spectator.Category{{Name: "Algorithms", Cells: []spectator.Cell{{Value: 100, Picked: true}}}},
+	Teams:      []spectator.Team{{Name: "Red", Score: 100, Control: true}},
+	Clue:       &spectator.Clue{Category: "Algorithms", Value: 100, Question: "?", Answer: "!", Revealed: true},
+}
+
+func (o *Overlay) render(st spectator.State) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	files["state.json"] = append(data, '\n')
+	for name, t := range o.templates {
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, st); err != nil {
+			return nil, fmt.Errorf("overlay template %s: %w", name, err)
+		}
+		files[name] = buf.Bytes()
+	}
+	return files, nil
+}
+
+// Update renders the engine's state and queues changed files to be written.
+// It is a no-op on a nil Overlay.
+func (o *Overlay) Update(e *engine.Engine) {
+	if o == nil {
+		return
+	}
+	files, err := o.render(spectator.Snapshot(e, plainClue))
+	if err != nil {
+		o.fail(err.Error(), err)
+		return
+	}
+	// merge with anything not written yet, newest wins
+	select {
+	case old := <-o.pending:
+		for name, data := range files {
+			old[name] = data
+		}
+		files = old
+	default:
+	}
+	o.pending <- files
+}
+
+func (o *Overlay) run() {
+	defer close(o.done)
+	for files := range o.pending {
+		for name, data := range files {
+			if bytes.Equal(o.last[name], data) {
+				continue
+			}
+			// a failed file is tried again on the next update
+			if err := writeFileAtomic(filepath.Join(o.dir, name), data); err != nil {
+				o.fail("writing "+name, fmt.Errorf("writing %s: %w", name, err))
+				continue
+			}
+			o.last[name] = data
+		}
+	}
+}
+
+// fail records err unless a failure with the same key already has been.
+// Write errors are keyed by file, as their messages name temporary files.
+func (o *Overlay) fail(key string, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.failed[key] {
+		return
+	}
+	o.failed[key] = true
+	o.errs = append(o.errs, err)
+}
+
+// NewErrors returns the failures recorded since it was last called, joined
+// with "; ", or nil if there were none. It is nil on a nil Overlay.
+func (o *Overlay) NewErrors() error {
+	if o == nil {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	errs := o.errs[o.reported:]
+	o.reported = len(o.errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
+
+// Close lets the writer finish the last update, so the files show the final
+// scores. A stuck disk or network share only holds up exiting for half a
+// second.
+func (o *Overlay) Close() {
+	if o == nil {
+		return
+	}
+	close(o.pending)
+	select {
+	case <-o.done:
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+// writeFileAtomic replaces path with data by renaming a temporary file over
+// it, so readers see either the old or the new contents
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
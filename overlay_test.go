@@ -0,0 +1,139 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maristcomputersociety/tuipardy/engine"
+)
+
+// waitFile waits until path holds want
+func waitFile(t *testing.T, path, want string) {
+	t.Helper()
+	var got []byte
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if got, _ = os.ReadFile(path); string(got) == want {
+			return
+		}
+	}
+	t.Fatalf("%s holds %q, want %q", filepath.Base(path), got, want)
+}
+
+// waitErrors waits for the overlay to record a failure
+func waitErrors(t *testing.T, o *Overlay) error {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if err := o.NewErrors(); err != nil {
+			return err
+		}
+	}
+	t.Fatal("no error recorded")
+	return nil
+}
+
+func TestOverlayFiles(t *testing.T) {
+	dir, templates := t.TempDir(), t.TempDir()
+	tmpl := `{{.Phase}}{{range .Teams}} {{.Name}}={{.Score}}{{end}}`
+	if err := os.WriteFile(filepath.Join(templates, "ticker.txt.tmpl"), []byte(tmpl), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	o, err := NewOverlay(dir, templates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := newTestGame(t).g.e
+	o.Update(e)
+	waitFile(t, filepath.Join(dir, "scores.txt"), "Red: 0\nBlue: 0\n")
+	waitFile(t, filepath.Join(dir, "ticker.txt"), "board Red=0 Blue=0")
+	waitFile(t, filepath.Join(dir, "current_clue.txt"), "")
+
+	e.Do(engine.Open{})
+	e.Do(engine.Reveal{})
+	e.Do(engine.Adjust{Changes: []engine.ScoreChange{{Team: 1, Op: '+', Value: 100}}})
+	o.Update(e)
+	o.Close()
+	waitFile(t, filepath.Join(dir, "scores.txt"), "Red: 0\nBlue: 100 ◀\n")
+	if got, _ := os.ReadFile(filepath.Join(dir, "current_clue.txt")); !strings.Contains(string(got), "Answer: ") {
+		t.Errorf("current_clue.txt has no answer after it was revealed:\n%s", got)
+	}
+	if err := o.NewErrors(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOverlayWriteError(t *testing.T) {
+	dir := t.TempDir()
+	o, err := NewOverlay(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer o.Close()
+	// a directory in the way can't be replaced by the file
+	blocked := filepath.Join(dir, "scores.txt")
+	if err := os.Mkdir(blocked, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	e := newTestGame(t).g.e
+	o.Update(e)
+	if err := waitErrors(t, o); !strings.Contains(err.Error(), "writing scores.txt") {
+		t.Errorf("error %v, want one about scores.txt", err)
+	}
+	// the other files are still written
+	mustRead(t, filepath.Join(dir, "state.json"))
+
+	// the same failure again isn't reported twice
+	o.Update(e)
+	time.Sleep(50 * time.Millisecond)
+	if err := o.NewErrors(); err != nil {
+		t.Errorf("reported again: %v", err)
+	}
+
+	// nothing changed, but the file that failed is written this time
+	if err := os.Remove(blocked); err != nil {
+		t.Fatal(err)
+	}
+	o.Update(e)
+	waitFile(t, blocked, "Red: 0\nBlue: 0\n")
+}
+
+func TestOverlayTemplateError(t *testing.T) {
+	templates := t.TempDir()
+	// fine in the states checked at startup, but not with two teams on the board
+	tmpl := `{{if eq .Phase "board"}}{{index .Teams 5}}{{end}}`
+	if err := os.WriteFile(filepath.Join(templates, "bad.txt.tmpl"), []byte(tmpl), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	o, err := NewOverlay(t.TempDir(), templates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer o.Close()
+	e := newTestGame(t).g.e
+	o.Update(e)
+	o.Update(e)
+	err = o.NewErrors()
+	if err == nil || !strings.Contains(err.Error(), "overlay template bad.txt") {
+		t.Fatalf("error %v, want one about bad.txt", err)
+	}
+	if strings.Count(err.Error(), "overlay template bad.txt") != 1 {
+		t.Errorf("the same failure is listed more than once: %v", err)
+	}
+	if err := o.NewErrors(); err != nil {
+		t.Errorf("reported again: %v", err)
+	}
+}
+
+// mustRead waits for path to be written and returns it
+func mustRead(t *testing.T, path string) string {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if data, err := os.ReadFile(path); err == nil {
+			return string(data)
+		}
+	}
+	t.Fatalf("%s never written", path)
+	return ""
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// passthroughOp is a raw escape sequence placed at a cell position
+type passthroughOp struct {
+	x, y, w, h int
+	data       string // written with the cursor at (x, y)
+	erase      string // written when the op is retired, e.g. a kitty delete
+}
+
+// Passthrough writes escape sequences tcell doesn't know about (kitty graphics,
+// OSC 66 text sizing) to the terminal in step with tcell's own output. Ops are
+// queued while drawing and flushed after Show; the cells they cover are locked
+// so tcell doesn't paint over them, and unlocked again once the op goes away.
+type Passthrough struct {
+	s       tcell.Screen
+	out     io.Writer
+	pending []passthroughOp
+	shown   []passthroughOp
+	stale   bool
+}
+
+// NewPassthrough returns a passthrough writing to the screen's tty. Screens
+// without a tty (e.g. simulation screens) get a disabled passthrough.
+func NewPassthrough(s tcell.Screen) *Passthrough {
+	p := &Passthrough{s: s}
+	if tty, ok := s.Tty(); ok {
+		p.out = tty
+	}
+	return p
+}
+
+// Enabled reports whether raw output can reach the terminal
+func (p *Passthrough) Enabled() bool { return p != nil && p.out != nil }
+
+// Queue schedules data to be written at cell (x, y) after the next Show,
+// reserving the w×h cells it covers.
+func (p *Passthrough) Queue(x, y, w, h int, data, erase string) {
+	if !p.Enabled() {
+		return
+	}
+	p.pending = append(p.pending, passthroughOp{x: x, y: y, w: w, h: h, data: data, erase: erase})
+}
+
+// Drop discards the ops queued since the last Flush, e.g. when something is
+// drawn over them.
+func (p *Passthrough) Drop() {
+	if p != nil {
+		p.pending = nil
+	}
+}
+
+// Invalidate forces every op to be written again on the next Flush. Call it
+// whenever tcell repaints the whole screen, e.g. after a resize.
+func (p *Passthrough) Invalidate() {
+	if p != nil {
+		p.stale = true
+	}
+}
+
+// Flush writes the queued ops if they differ from what is on screen.
+func (p *Passthrough) Flush() {
+	if !p.Enabled() {
+		return
+	}
+	pending := p.pending
+	p.pending = nil
+	if !p.stale && sameOps(pending, p.shown) {
+		return
+	}
+
+	var b strings.Builder
+	if len(p.shown) > 0 {
+		for _, op := range p.shown {
+			b.WriteString(op.erase)
+			p.s.LockRegion(op.x, op.y, op.w, op.h, false)
+		}
+		io.WriteString(p.out, b.String())
+		b.Reset()
+		// let tcell repaint what the retired ops were covering
+		p.s.Show()
+	}
+
+	if len(pending) > 0 {
+		b.WriteString("\x1b7") // save cursor
+		for _, op := range pending {
+			p.s.LockRegion(op.x, op.y, op.w, op.h, true)
+			fmt.Fprintf(&b, "\x1b[%d;%dH", op.y+1, op.x+1)
+			b.WriteString(op.data)
+		}
+		b.WriteString("\x1b8") // restore cursor
+		io.WriteString(p.out, b.String())
+	}
+
+	p.shown = pending
+	p.stale = false
+}
+
+func sameOps(a, b []passthroughOp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sgr returns the escape sequence selecting a style's colors and attributes,
+// with colors downgraded to what a terminal with the given color count shows
+func sgr(st tcell.Style, colors int) string {
+	fg, bg, attrs := st.Decompose()
+	params := []string{"0"}
+	for _, a := range []struct {
+		mask  tcell.AttrMask
+		param string
+	}{
+		{tcell.AttrBold, "1"},
+		{tcell.AttrDim, "2"},
+		{tcell.AttrItalic, "3"},
+		{tcell.AttrUnderline, "4"},
+		{tcell.AttrReverse, "7"},
+	} {
+		if attrs&a.mask != 0 {
+			params = append(params, a.param)
+		}
+	}
+	if c := sgrColor(fg, 38, colors); c != "" {
+		params = append(params, c)
+	}
+	if c := sgrColor(bg, 48, colors); c != "" {
+		params = append(params, c)
+	}
+	return "\x1b[" + strings.Join(params, ";") + "m"
+}
+
+func sgrColor(c tcell.Color, base, colors int) string {
+	switch {
+	case !c.Valid() || colors < 8:
+		return ""
+	case c.IsRGB() && colors >= 1<<24:
+		r, g, b := c.RGB()
+		return fmt.Sprintf("%d;2;%d;%d;%d", base, r, g, b)
+	}
+	idx := int(c - tcell.ColorValid)
+	if c.IsRGB() || idx >= colors {
+		// nearest color in the palette the terminal has
+		palette := make([]tcell.Color, min(colors, 256))
+		for i := range palette {
+			palette[i] = tcell.PaletteColor(i)
+		}
+		idx = int(tcell.FindColor(c.TrueColor(), palette) - tcell.ColorValid)
+	}
+	return fmt.Sprintf("%d;5;%d", base, idx)
+}
+
+// IsTextSizingSupported reports whether the terminal understands the kitty
+// text sizing protocol (OSC 66). Only kitty itself implements it so far.
+func IsTextSizingSupported() bool {
+	return os.Getenv("KITTY_WINDOW_ID") != ""
+}
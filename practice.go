@@ -0,0 +1,316 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/maristcomputersociety/tuipardy/engine"
+)
+
+// Practice mode is a solo game: the player types a response to each clue,
+// which is judged against the answer and its alternates, and a summary is
+// shown once the board is cleared or the player quits.
+
+// practice outcomes
+const (
+	practiceCorrect = iota
+	practiceWrong
+	practicePassed
+)
+
+type practiceResult struct {
+	q        *Question
+	response string
+	outcome  int
+}
+
+// practiceOff are the board actions a practice game has no use for. The
+// player's score is judged rather than kept by a host, so there are no score
+// commands to cancel, no changes to undo and no teams to manage or pass to.
+var practiceOff = []string{ActionCancel, ActionTeams, ActionUndo, ActionControl}
+
+// practiceSession is the state of a practice game
+type practiceSession struct {
+	results []practiceResult
+	input   string // response being typed
+	judged  bool   // the open clue has been answered or passed
+}
+
+// NewPracticeGame sets up a practice game on a board for one player
+func NewPracticeGame(b *Board, cfg *Config, player string) (*Game, error) {
+	solo := *cfg
+	solo.Teams = []string{player}
+	solo.MinTeams, solo.MaxTeams = 1, 1
+	g, err := NewGame(b, &solo)
+	if err != nil {
+		return nil, err
+	}
+	g.practice = &practiceSession{}
+	g.msg = g.boardHint()
+	return g, nil
+}
+
+// handlePracticeInput edits the response being typed to the open clue.
+// Enter submits it; Enter with nothing typed, or cancel, passes.
+func (g *Game) handlePracticeInput(key tcell.Key, r rune) bool {
+	p := g.practice
+	switch action := g.keys.Action(PhaseQuestion, key, r); {
+	case key == tcell.KeyCtrlC:
+		return true
+	case key == tcell.KeyEnter:
+		g.judgePractice(trimSpaces(p.input))
+	case key == tcell.KeyBackspace || key == tcell.KeyBackspace2:
+		p.input = dropLastCluster(p.input)
+	case key == tcell.KeyRune:
+		p.input += string(r)
+	case action == ActionScrollUp:
+		g.scrollClue(-g.cluePage)
+	case action == ActionScrollDown:
+		g.scrollClue(g.cluePage)
+	case action == ActionBack || g.keys.Action(PhaseBoard, key, r) == ActionCancel:
+		g.judgePractice("")
+	}
+	return false
+}
+
+// judgePractice scores a response to the open clue and reveals the answer.
+// An empty response passes without scoring.
+func (g *Game) judgePractice(response string) {
+	p := g.practice
+	q := g.e.Current()
+	res := practiceResult{q: q, response: response, outcome: practicePassed}
+	if response != "" {
+		res.outcome = practiceWrong
+		op := byte('-')
+		if answerMatches(response, q) {
+			res.outcome = practiceCorrect
+			op = '+'
+		}
+		g.do(engine.Adjust{
+			Changes: []engine.ScoreChange{{Team: 0, Op: op, Value: q.Value}},
+			Label:   fmt.Sprintf("response to %s %d", q.Category, q.Value),
+		})
+	}
+	p.results = append(p.results, res)
+	p.judged = true
+	p.input = ""
+	if !g.e.AnswerShown() {
+		g.do(engine.Reveal{})
+	}
+
+	back := g.keyNames(ActionBack)
+	switch res.outcome {
+	case practiceCorrect:
+		g.flashMsg("✓ correct! +%d. %s to return", q.Value, back)
+	case practiceWrong:
+		g.flashMsg("✗ not quite, you said %q. -%d. %s to return", response, q.Value, back)
+	default:
+		g.flashMsg("passed. %s to return", back)
+	}
+}
+
+// practiceFinished reports whether every clue on the board has been opened
+func (g *Game) practiceFinished() bool {
+	for _, cat := range g.e.Board().Categories {
+		for _, q := range cat.Questions {
+			if !q.Picked {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// showSummary ends a practice game on its summary screen
+func (g *Game) showSummary() {
+	g.phase = PhaseSummary
+	g.narrate("%s", strings.Join(g.practiceSummary(), ". "))
+}
+
+// practiceSummary describes how the session went, one line per entry
+func (g *Game) practiceSummary() []string {
+	p := g.practice
+	var counts [3]int
+	for _, r := range p.results {
+		counts[r.outcome]++
+	}
+	total := 0
+	for _, cat := range g.e.Board().Categories {
+		total += len(cat.Questions)
+	}
+	score := 0
+	if teams := g.e.Teams(); len(teams) > 0 {
+		score = teams[0].Score
+	}
+	lines := []string{
+		"practice summary",
+		fmt.Sprintf("score %d: %d correct, %d wrong, %d passed, %d of %d clues tried",
+			score, counts[practiceCorrect], counts[practiceWrong], counts[practicePassed], len(p.results), total),
+	}
+	var missed []string
+	for _, r := range p.results {
+		if r.outcome == practiceCorrect {
+			continue
+		}
+		line := fmt.Sprintf("%s $%d: %s", r.q.Category, r.q.Value, clueText(r.q.A))
+		if r.outcome == practiceWrong {
+			line += fmt.Sprintf(" (you said %q)", r.response)
+		}
+		missed = append(missed, line)
+	}
+	if len(missed) > 0 {
+		lines = append(lines, "", "to review:")
+		lines = append(lines, missed...)
+	}
+	return lines
+}
+
+// drawSummary renders the summary screen at the end of a practice or study
+// session
+func drawSummary(s tcell.Screen, lines []string) {
+	w, h := s.Size()
+	lines = append(lines, "", "press any key to exit")
+	switch {
+	case h <= 0:
+		return
+	case len(lines) > h && h < 3:
+		// the title and how to leave, or just how to leave
+		lines = append(lines[:h-1:h-1], lines[len(lines)-1])
+	case len(lines) > h:
+		lines = append(lines[:h-2], "…", lines[len(lines)-1])
+	}
+	y := max(0, (h-len(lines))/2)
+	for i, line := range lines {
+		st := stylePrompt().Bold(i == 0)
+		drawCenteredText(s, 0, y+i, w, 1, st, truncateText(line, w-2))
+	}
+}
+
+// answerMatches reports whether a typed response matches a clue's answer or
+// one of its alternates. Both are normalized first (see normalizeAnswer), and
+// answers of five letters or more may be off by one edit per five letters.
+// Shorter answers and answers with digits must match exactly.
+func answerMatches(response string, q *Question) bool {
+	resp := normalizeAnswer(response)
+	if resp == "" {
+		return false
+	}
+	for _, answer := range acceptedAnswers(q) {
+		want := normalizeAnswer(answer)
+		if want == "" {
+			continue
+		}
+		if resp == want {
+			return true
+		}
+		n := len([]rune(want))
+		if n < 5 || strings.IndexFunc(want, unicode.IsDigit) >= 0 {
+			continue
+		}
+		if editDistance(resp, want) <= n/5 {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptedAnswers returns the answers a response is judged against: the
+// answer, the answer without notes in parentheses, the notes themselves
+// (e.g. "Go (Golang)" also accepts "Go" and "Golang"), and the alternates
+func acceptedAnswers(q *Question) []string {
+	answer := clueText(q.A)
+	answers := []string{answer}
+	if outside, notes := splitNotes(answer); len(notes) > 0 {
+		answers = append(answers, outside)
+		answers = append(answers, notes...)
+	}
+	for _, alt := range q.Alternates {
+		answers = append(answers, clueText(alt))
+	}
+	return answers
+}
+
+// splitNotes separates parenthesized notes that follow a space from the rest
+// of an answer, leaving parentheses in terms like "O(n^2)" alone
+func splitNotes(answer string) (string, []string) {
+	var outside, note strings.Builder
+	var notes []string
+	depth := 0
+	prev := ' '
+	for _, r := range answer {
+		switch {
+		case r == '(' && depth == 0 && unicode.IsSpace(prev):
+			depth = 1
+		case r == '(' && depth > 0:
+			depth++
+			note.WriteRune(r)
+		case r == ')' && depth == 1:
+			depth = 0
+			notes = append(notes, note.String())
+			note.Reset()
+		case r == ')' && depth > 1:
+			depth--
+			note.WriteRune(r)
+		case depth > 0:
+			note.WriteRune(r)
+		default:
+			outside.WriteRune(r)
+		}
+		prev = r
+	}
+	return outside.String(), notes
+}
+
+// question words and articles dropped from the start of a response
+var (
+	answerQuestionWords = map[string]bool{"what": true, "who": true, "where": true, "when": true, "which": true}
+	answerVerbs         = map[string]bool{"is": true, "are": true, "was": true, "were": true, "s": true}
+	answerArticles      = map[string]bool{"a": true, "an": true, "the": true}
+)
+
+// normalizeAnswer lowercases text, turns punctuation into spaces (keeping +
+// and # for names like C++ and C#), and drops a leading "what is", "who
+// are" etc. and a leading article
+func normalizeAnswer(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+' || r == '#' {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, text)
+	words := strings.Fields(text)
+	if len(words) > 1 && answerQuestionWords[words[0]] {
+		words = words[1:]
+		if len(words) > 1 && answerVerbs[words[0]] {
+			words = words[1:]
+		}
+	}
+	if len(words) > 1 && answerArticles[words[0]] {
+		words = words[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// editDistance is the Levenshtein distance between a and b, in runes
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestNormalizeAnswer(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"Paris", "paris"},
+		{"  What is   Paris?  ", "paris"},
+		{"who are the Beatles", "beatles"},
+		{"What's TCP/IP", "tcp ip"},
+		{"what", "what"},  // nothing left to drop it for
+		{"what is", "is"}, // the question word goes, the last word stays
+		{"The", "the"},    // a lone article stays
+		{"an apple a day", "apple a day"},
+		{"C++", "c++"},
+		{"what is C#?", "c#"},
+		{"Ça-va", "ça va"},
+		{"ÉCOLE", "école"},
+		{"1,000", "1 000"},
+		{"", ""},
+		{"?!", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeAnswer(tt.text); got != tt.want {
+			t.Errorf("normalizeAnswer(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSplitNotes(t *testing.T) {
+	tests := []struct {
+		answer      string
+		wantOutside string
+		wantNotes   []string
+	}{
+		{"Go", "Go", nil},
+		{"Go (Golang)", "Go ", []string{"Golang"}},
+		{"(Golang) Go", " Go", []string{"Golang"}},
+		{"HTTP (HyperText (Transfer) Protocol)", "HTTP ", []string{"HyperText (Transfer) Protocol"}},
+		{"f(x)", "f(x)", nil}, // not after a space
+		{"a (b) c (d)", "a  c ", []string{"b", "d"}},
+		{"open (unclosed", "open ", nil},
+		{"stray) paren", "stray) paren", nil},
+		{"café (☕)", "café ", []string{"☕"}},
+	}
+	for _, tt := range tests {
+		outside, notes := splitNotes(tt.answer)
+		if outside != tt.wantOutside || !slices.Equal(notes, tt.wantNotes) {
+			t.Errorf("splitNotes(%q) = %q, %q, want %q, %q", tt.answer, outside, notes, tt.wantOutside, tt.wantNotes)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"same", "same", 0},
+		{"ab", "ba", 2},
+		{"café", "cafe", 1}, // runes, not bytes
+		{"日本語", "日本", 1},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := editDistance(tt.b, tt.a); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestAnswerMatches(t *testing.T) {
+	tests := []struct {
+		answer     string
+		alternates []string
+		response   string
+		want       bool
+	}{
+		{"Paris", nil, "paris", true},
+		{"Paris", nil, "What is Paris?", true},
+		{"Paris", nil, "Pariss", true}, // one edit allowed from 5 letters
+		{"Paris", nil, "Parsi", false},
+		{"Rust", nil, "Bust", false}, // 4 letters must match exactly
+		{"Rust", nil, "Just", false},
+		{"Rust", nil, "rust", true},
+		{"Rome", nil, "Rone", false},
+		{"Ant", nil, "Anr", false},
+		{"Kotlin", nil, "Kotlyn", true},
+		{"Kotlin", nil, "Katlyn", false},
+		{"Python", nil, "Pythn", true},
+		{"Linus Torvalds", nil, "linus torvolds", true},
+		{"Linus Torvalds", nil, "linux torvolds", true}, // 14 letters, 2 edits
+		{"Linus Torvalds", nil, "linux torvoldz", false},
+		{"Go (Golang)", nil, "go", true},
+		{"Go (Golang)", nil, "golang", true},
+		{"Go (Golang)", nil, "Go (Golang)", true},
+		{"HTTP", []string{"HyperText Transfer Protocol"}, "hypertext transfer protocol", true},
+		{"*bold* answer", nil, "bold answer", true}, // markup is ignored
+		{"1969", nil, "1968", false},                // numbers must match exactly
+		{"Apollo 11", nil, "Apollo 12", false},
+		{"Apollo 11", nil, "apollo 11", true},
+		{"C++", nil, "C", false},
+		{"C#", nil, "c#", true},
+		{"Paris", nil, "", false},
+		{"Paris", nil, "what is", false},
+		{"?", nil, "?", false}, // nothing left to compare
+	}
+	for _, tt := range tests {
+		q := &Question{A: tt.answer, Alternates: tt.alternates}
+		if got := answerMatches(tt.response, q); got != tt.want {
+			t.Errorf("answer %q, response %q: got %v, want %v", tt.answer, tt.response, got, tt.want)
+		}
+	}
+}
+
+func TestDrawSummarySmall(t *testing.T) {
+	lines := []string{"practice summary", "1 right", "2 wrong", "3 passed"}
+	tests := []struct {
+		h    int
+		want []string // rows from the top, trimmed
+	}{
+		{0, nil},
+		{1, []string{"press any key to exit"}},
+		{2, []string{"practice summary", "press any key to exit"}},
+		{3, []string{"practice summary", "…", "press any key to exit"}},
+		{5, []string{"practice summary", "1 right", "2 wrong", "…", "press any key to exit"}},
+		{6, []string{"practice summary", "1 right", "2 wrong", "3 passed", "", "press any key to exit"}},
+		{8, []string{"", "practice summary", "1 right", "2 wrong", "3 passed", "", "press any key to exit", ""}},
+	}
+	for _, tt := range tests {
+		s := tcell.NewSimulationScreen("UTF-8")
+		if err := s.Init(); err != nil {
+			t.Fatal(err)
+		}
+		s.SetSize(40, tt.h)
+		drawSummary(s, slices.Clone(lines))
+		s.Show()
+		cells, w, h := s.GetContents()
+		var got []string
+		for y := range h {
+			var row strings.Builder
+			for _, c := range cells[y*w : (y+1)*w] {
+				row.WriteString(string(c.Runes))
+			}
+			got = append(got, strings.TrimSpace(row.String()))
+		}
+		s.Fini()
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%d rows: got %q, want %q", tt.h, got, tt.want)
+		}
+	}
+}
+
+func TestPracticeKeysOff(t *testing.T) {
+	g, err := NewPracticeGame(loadSampleBoard(t), testConfig(), "Solo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := NewHarness(g, 100, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	h.Key(tcell.KeyEnter, 0)
+	h.Type("not the answer")
+	h.Key(tcell.KeyEnter, 0)
+	h.Key(tcell.KeyEsc, 0)
+	h.Type("utc")
+	if g.teamMgr != nil {
+		t.Errorf("the team manager opened in practice")
+	}
+	if got := g.e.Teams()[0].Score; got >= 0 {
+		t.Errorf("score %d after a wrong response and undo, want it still down", got)
+	}
+	if len(g.practice.results) != 1 {
+		t.Errorf("%d results, want 1", len(g.practice.results))
+	}
+
+	h.Type("?")
+	for _, row := range g.helpRows() {
+		if strings.Contains(row[1], "score") || strings.Contains(row[1], "team") {
+			t.Errorf("practice help lists %q", row)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Package spectator serves a read-only live view of a game to web browsers,
+// e.g. on a projector. The page gets the game state as JSON over Server-Sent
+// Events. Clue text is only sent once the clue is opened, and its answer
+// only once it is revealed.
+package spectator
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/maristcomputersociety/tuipardy/engine"
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+// State is what spectators see of the game
+type State struct {
+	Phase      string     `json:"phase"`
+	Categories []Category `json:"categories"`
+	Teams      []Team     `json:"teams"`
+	Clue       *Clue      `json:"clue,omitempty"`
+}
+
+type Category struct {
+	Name  string `json:"name"`
+	Cells []Cell `json:"cells"`
+}
+
+type Cell struct {
+	Value  int  `json:"value"`
+	Picked bool `json:"picked"`
+}
+
+type Team struct {
+	Name    string `json:"name"`
+	Score   int    `json:"score"`
+	Control bool   `json:"control"` // picks the next clue
+}
+
+// Clue is the open clue
+type Clue struct {
+	Category string `json:"category"`
+	Value    int    `json:"value"`
+	Question string `json:"question"`
+	Answer   string `json:"answer,omitempty"` // empty until revealed
+	Revealed bool   `json:"revealed"`
+	Image    string `json:"image,omitempty"` // URL of the image being shown
+}
+
+// Server keeps the latest state and pushes it to connected browsers
+type Server struct {
+	// Format turns clue text into what spectators see, e.g. to strip
+	// markup. Nil leaves it as is.
+	Format func(string) string
+
+	mu      sync.Mutex
+	state   []byte // latest State as JSON
+	image   string // path of the image being shown
+	version int    // bumped on every update, used in image URLs
+	clients map[chan []byte]struct{}
+}
+
+func New() *Server {
+	return &Server{state: []byte("{}"), clients: map[chan []byte]struct{}{}}
+}
+
+// Update snapshots the engine's state and sends it to every spectator. Call
+// it from the goroutine driving the engine, e.g. from an Engine.Subscribe
+// callback.
+func (s *Server) Update(e *engine.Engine) {
+	st, image := Snapshot(e, s.Format), shownImage(e)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version++
+	if st.Clue != nil && image != "" {
+		st.Clue.Image = fmt.Sprintf("/image?v=%d", s.version)
+	}
+	data, err := json.Marshal(st)
+	if err != nil {
+		return
+	}
+	s.state, s.image = data, image
+	for c := range s.clients {
+		// a client that hasn't taken the last update only needs the newest
+		select {
+		case <-c:
+		default:
+		}
+		c <- data
+	}
+}
+
+// Snapshot returns what spectators see of the engine's state, passing clue
+// text through format (nil leaves it as is). Clue.Image is left empty; it is
+// only known to a Server.
+func Snapshot(e *engine.Engine, format func(string) string) State {
+	if format == nil {
+		format = func(text string) string { return text }
+	}
+	st := State{Phase: e.Phase().String()}
+	for _, cat := range e.Board().Categories {
+		c := Category{Name: cat.Name}
+		for _, q := range cat.Questions {
+			c.Cells = append(c.Cells, Cell{Value: q.Value, Picked: q.Picked})
+		}
+		st.Categories = append(st.Categories, c)
+	}
+	for _, t := range e.Teams() {
+		st.Teams = append(st.Teams, Team{Name: t.Name, Score: t.Score, Control: t == e.Control()})
+	}
+	if q := e.Current(); q != nil {
+		st.Clue = &Clue{Category: q.Category, Value: q.Value, Question: format(q.Q)}
+		if e.AnswerShown() {
+			st.Clue.Revealed = true
+			st.Clue.Answer = format(q.A)
+		}
+	}
+	return st
+}
+
+// shownImage is the path of the image on screen with the open clue, if any
+func shownImage(e *engine.Engine) string {
+	q := e.Current()
+	if q == nil {
+		return ""
+	}
+	return q.Image(e.AnswerShown())
+}
+
+// Handler serves the spectator page at /, the state as JSON at /state, the
+// Server-Sent Events stream at /events and the current image at /image
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(indexHTML)
+	})
+	mux.HandleFunc("GET /state", s.serveState)
+	mux.HandleFunc("GET /events", s.serveEvents)
+	mux.HandleFunc("GET /image", s.serveImage)
+	return mux
+}
+
+func (s *Server) serveState(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	data := s.state
+	s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	c := make(chan []byte, 1)
+	s.mu.Lock()
+	c <- s.state
+	s.clients[c] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, c)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	for {
+		select {
+		case data := <-c:
+			fmt.Fprintf(w, "event: state\ndata: %s\n\n", data)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// serveImage serves the image of the open clue. Only that one file can be
+// fetched, whatever the URL asks for.
+func (s *Server) serveImage(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	path := s.image
+	s.mu.Unlock()
+	if path == "" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeFile(w, r, path)
+}
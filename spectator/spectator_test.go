@@ -0,0 +1,206 @@
+package spectator
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maristcomputersociety/tuipardy/board"
+	"github.com/maristcomputersociety/tuipardy/engine"
+)
+
+// testGame returns a game with two teams on the board, its first clue
+// showing the image at imagePath when that isn't empty
+func testGame(t *testing.T, imagePath string) *engine.Engine {
+	t.Helper()
+	var csv strings.Builder
+	for c := range board.ExpectedCategories {
+		for r := range board.QuestionsPerCategory {
+			fmt.Fprintf(&csv, "Cat %d,%d,Question %d-%d,Answer %d-%d\n", c, (r+1)*100, c, r, c, r)
+		}
+	}
+	b, err := board.Read(strings.NewReader(csv.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Categories[0].Questions[0].ImagePath = imagePath
+	e, err := engine.New(b, engine.DefaultRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Do(engine.StartGame{Teams: []string{"Red", "Blue"}}); err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+// testServer serves a Server following e
+func testServer(t *testing.T, e *engine.Engine) *httptest.Server {
+	t.Helper()
+	srv := New()
+	srv.Update(e)
+	e.Subscribe(func(engine.Event) { srv.Update(e) })
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func getState(t *testing.T, url string) State {
+	t.Helper()
+	resp, err := http.Get(url + "/state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var st State
+	if err := json.NewDecoder(resp.Body).Decode(&st); err != nil {
+		t.Fatal(err)
+	}
+	return st
+}
+
+func TestStateHidesAnswer(t *testing.T) {
+	e := testGame(t, "")
+	ts := testServer(t, e)
+	tests := []struct {
+		name         string
+		cmd          engine.Command
+		wantPhase    string
+		wantQuestion string // empty for no clue
+		wantAnswer   string
+	}{
+		{"board", nil, "board", "", ""},
+		{"opened", engine.Open{}, "question", "Question 0-0", ""},
+		{"revealed", engine.Reveal{}, "question", "Question 0-0", "Answer 0-0"},
+		{"hidden again", engine.Reveal{}, "question", "Question 0-0", ""},
+		{"closed", engine.Back{}, "board", "", ""},
+	}
+	for _, tt := range tests {
+		if tt.cmd != nil {
+			if err := e.Do(tt.cmd); err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+		}
+		st := getState(t, ts.URL)
+		if st.Phase != tt.wantPhase {
+			t.Errorf("%s: phase %q, want %q", tt.name, st.Phase, tt.wantPhase)
+		}
+		if tt.wantQuestion == "" {
+			if st.Clue != nil {
+				t.Errorf("%s: clue %+v sent with no clue open", tt.name, st.Clue)
+			}
+			continue
+		}
+		if st.Clue == nil {
+			t.Fatalf("%s: no clue sent", tt.name)
+		}
+		if st.Clue.Question != tt.wantQuestion || st.Clue.Answer != tt.wantAnswer || st.Clue.Revealed != (tt.wantAnswer != "") {
+			t.Errorf("%s: clue %+v, want question %q answer %q", tt.name, st.Clue, tt.wantQuestion, tt.wantAnswer)
+		}
+	}
+}
+
+func TestEventsStream(t *testing.T) {
+	e := testGame(t, "")
+	ts := testServer(t, e)
+	resp, err := http.Get(ts.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("content type %q", ct)
+	}
+
+	frames := make(chan State)
+	go func() {
+		defer close(frames)
+		r := bufio.NewReader(resp.Body)
+		for {
+			event, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			data, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			r.ReadString('\n') // the blank line ending the frame
+			var st State
+			if event != "event: state\n" || json.Unmarshal([]byte(strings.TrimPrefix(data, "data: ")), &st) != nil {
+				t.Errorf("bad frame %q %q", event, data)
+				return
+			}
+			frames <- st
+		}
+	}()
+	next := func() State {
+		t.Helper()
+		select {
+		case st, ok := <-frames:
+			if !ok {
+				t.Fatal("stream ended")
+			}
+			return st
+		case <-time.After(5 * time.Second):
+			t.Fatal("no frame")
+		}
+		return State{}
+	}
+
+	if st := next(); st.Phase != "board" || st.Clue != nil {
+		t.Errorf("first frame %+v, want the board", st)
+	}
+	if err := e.Do(engine.Open{}); err != nil {
+		t.Fatal(err)
+	}
+	if st := next(); st.Clue == nil || st.Clue.Question != "Question 0-0" || st.Clue.Answer != "" {
+		t.Errorf("frame after opening %+v, want the clue without its answer", st.Clue)
+	}
+}
+
+func TestImage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clue.png")
+	if err := os.WriteFile(path, []byte("not really a png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	e := testGame(t, path)
+	ts := testServer(t, e)
+	get := func() (int, string) {
+		t.Helper()
+		resp, err := http.Get(ts.URL + "/image")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	if code, _ := get(); code != http.StatusNotFound {
+		t.Errorf("no clue open: status %d, want 404", code)
+	}
+	if err := e.Do(engine.Open{}); err != nil {
+		t.Fatal(err)
+	}
+	if code, body := get(); code != http.StatusOK || body != "not really a png" {
+		t.Errorf("clue open: status %d body %q", code, body)
+	}
+	if st := getState(t, ts.URL); st.Clue == nil || !strings.HasPrefix(st.Clue.Image, "/image?v=") {
+		t.Errorf("state doesn't point at the image: %+v", st.Clue)
+	}
+	if err := e.Do(engine.Back{}); err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := get(); code != http.StatusNotFound {
+		t.Errorf("clue closed: status %d, want 404", code)
+	}
+}
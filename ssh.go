@@ -0,0 +1,602 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/maristcomputersociety/tuipardy/engine"
+	"golang.org/x/crypto/ssh"
+)
+
+// Players can join over SSH, each getting their own view of the host's game
+// drawn on a tcell screen over their PTY. The SSH user name picks the role:
+// "team2" (or just "2") takes team 2's seat and buzzes in with space or
+// enter, anything else watches. A seat stays with the team it was taken for
+// wherever the host later moves that team, and only one player can have it.
+// Remote screens are only ever touched from the host's loop; the SSH
+// goroutines just forward their events to it.
+
+// sshOutQueue is how many writes a remote screen may fall behind by before
+// the player is dropped, so one slow connection can't stall the game
+const sshOutQueue = 64
+
+// SSHHostKeyPath returns the default --ssh-host-key file, so players see
+// the same host key from one game to the next
+func SSHHostKeyPath() string { return userDataPath("ssh_host_ed25519_key") }
+
+// loadHostKey reads an SSH host key, generating an ed25519 key at path if
+// there is none yet
+func loadHostKey(path string) (ssh.Signer, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		block, err := ssh.MarshalPrivateKey(priv, "tuipardy host key")
+		if err != nil {
+			return nil, err
+		}
+		data = pem.EncodeToMemory(block)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	signer, err := ssh.ParsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("host key %s: %w", path, err)
+	}
+	return signer, nil
+}
+
+// loadAllowedKeys reads public keys in authorized_keys format
+func loadAllowedKeys(path string) (map[string]bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys := map[string]bool{}
+	for i, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+		if err != nil {
+			return nil, fmt.Errorf("allowed keys %s:%d: %w", path, i+1, err)
+		}
+		keys[string(key.Marshal())] = true
+	}
+	return keys, nil
+}
+
+// sshServer accepts SSH players into a game
+type sshServer struct {
+	g       *Game
+	config  *ssh.ServerConfig
+	allowed map[string]bool // nil lets anyone in to watch
+}
+
+// startSSH listens for SSH players on addr. Without an allowed keys file
+// anyone can connect, but only to watch; with one, only the listed keys can
+// connect and they may take team seats. The host key is loaded or created
+// and addr bound here, so those errors stop the game from starting.
+func startSSH(g *Game, addr, hostKeyPath, allowedKeysPath string) error {
+	srv, err := newSSHServer(g, hostKeyPath, allowedKeysPath)
+	if err != nil {
+		return err
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	go srv.serve(ln)
+	return nil
+}
+
+// newSSHServer loads the host key and allowed keys for g's SSH server
+func newSSHServer(g *Game, hostKeyPath, allowedKeysPath string) (*sshServer, error) {
+	signer, err := loadHostKey(hostKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	srv := &sshServer{g: g, config: &ssh.ServerConfig{}}
+	if allowedKeysPath == "" {
+		srv.config.NoClientAuth = true
+	} else {
+		if srv.allowed, err = loadAllowedKeys(allowedKeysPath); err != nil {
+			return nil, err
+		}
+		srv.config.PublicKeyCallback = func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if !srv.allowed[string(key.Marshal())] {
+				return nil, errors.New("key not allowed")
+			}
+			return nil, nil
+		}
+	}
+	srv.config.AddHostKey(signer)
+	return srv, nil
+}
+
+func (srv *sshServer) serve(ln net.Listener) {
+	// sessions need the host's screen to post their events to
+	<-srv.g.running
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go srv.handleConn(conn)
+	}
+}
+
+func (srv *sshServer) handleConn(nc net.Conn) {
+	conn, chans, reqs, err := ssh.NewServerConn(nc, srv.config)
+	if err != nil {
+		nc.Close()
+		return
+	}
+	defer conn.Close()
+	go ssh.DiscardRequests(reqs)
+
+	seat, note := 0, ""
+	if n, ok := seatNumber(conn.User()); ok {
+		if srv.allowed == nil {
+			note = "team seats need an allowed key, watching instead"
+		} else {
+			seat = n
+		}
+	}
+	for nch := range chans {
+		if nch.ChannelType() != "session" {
+			nch.Reject(ssh.UnknownChannelType, "only sessions are supported")
+			continue
+		}
+		ch, chReqs, err := nch.Accept()
+		if err != nil {
+			continue
+		}
+		go srv.handleSession(ch, chReqs, seat, note)
+	}
+}
+
+// seatNumber parses a team seat from an SSH user name, e.g. "team2" or "2"
+func seatNumber(user string) (int, bool) {
+	n, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(user), "team"))
+	return n, err == nil && n > 0
+}
+
+// ptyRequest is the payload of a "pty-req" request (RFC 4254 section 6.2)
+type ptyRequest struct {
+	Term          string
+	Columns, Rows uint32
+	Width, Height uint32
+	Modes         string
+}
+
+// windowChange is the payload of a "window-change" request (RFC 4254 section 6.7)
+type windowChange struct {
+	Columns, Rows uint32
+	Width, Height uint32
+}
+
+func (srv *sshServer) handleSession(ch ssh.Channel, reqs <-chan *ssh.Request, seat int, note string) {
+	tty := newSSHTty(ch)
+	term, started := "", false
+	for req := range reqs {
+		switch req.Type {
+		case "pty-req":
+			var p ptyRequest
+			if err := ssh.Unmarshal(req.Payload, &p); err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			term = p.Term
+			tty.setSize(int(p.Columns), int(p.Rows))
+			req.Reply(true, nil)
+		case "window-change":
+			var p windowChange
+			if err := ssh.Unmarshal(req.Payload, &p); err == nil {
+				tty.setSize(int(p.Columns), int(p.Rows))
+			}
+		case "shell":
+			if started {
+				req.Reply(false, nil)
+				continue
+			}
+			started = true
+			req.Reply(true, nil)
+			if term == "" {
+				fmt.Fprint(ch, "tuipardy needs a terminal, connect with ssh -t\r\n")
+				tty.Close()
+				continue
+			}
+			go srv.play(tty, term, seat, note)
+		default:
+			req.Reply(false, nil)
+		}
+	}
+}
+
+// play runs a remote player's screen, forwarding its events to the host
+func (srv *sshServer) play(tty *sshTty, term string, seat int, note string) {
+	ti, err := tcell.LookupTerminfo(term)
+	if err != nil {
+		ti, err = tcell.LookupTerminfo("xterm-256color")
+	}
+	if err != nil {
+		tty.Close()
+		return
+	}
+	s, err := tcell.NewTerminfoScreenFromTtyTerminfo(tty, ti)
+	if err == nil {
+		err = s.Init()
+	}
+	if err != nil {
+		fmt.Fprintf(tty.ch, "can't start the game screen: %v\r\n", err)
+		tty.Close()
+		return
+	}
+
+	g := srv.g
+	r := &remote{seat: seat, note: note, view: g.newView(s), tty: tty}
+	if !g.postEvent(newRemoteEvent(r, nil)) {
+		// the game ended before the player joined it
+		s.Fini()
+		return
+	}
+	for {
+		ev := s.PollEvent()
+		if ev == nil || !g.postEvent(newRemoteEvent(r, ev)) {
+			return
+		}
+	}
+}
+
+// remote is a player connected over SSH
+type remote struct {
+	view *Game
+	tty  *sshTty
+	seat int          // team number asked for at login, 0 to watch
+	team *engine.Team // the seat's team once joined, nil for a spectator
+	note string       // shown on the player's status line until their next key
+	clue *Question
+	gone bool
+}
+
+// remoteEvent carries a remote player's screen event to the host's loop
+type remoteEvent struct {
+	tcell.EventTime
+	r  *remote
+	ev tcell.Event // nil when the player joins
+}
+
+func newRemoteEvent(r *remote, ev tcell.Event) *remoteEvent {
+	e := &remoteEvent{r: r, ev: ev}
+	e.SetEventNow()
+	return e
+}
+
+// newView returns a game drawing g's engine on another screen. Views have no
+// images or text sizing since they can't know what the remote terminal does.
+func (g *Game) newView(s tcell.Screen) *Game {
+	v := &Game{clueView: clueView{keys: g.keys}, e: g.e, cfg: g.cfg}
+	v.setScreen(s)
+	return v
+}
+
+// handleRemote handles a remote player's join, leave or screen event
+func (g *Game) handleRemote(e *remoteEvent) {
+	r := e.r
+	if r.gone {
+		return
+	}
+	switch ev := e.ev.(type) {
+	case nil:
+		if err := g.takeSeat(r); err != nil {
+			r.gone = true
+			r.tty.farewell(err.Error())
+			r.view.s.Fini()
+			return
+		}
+		g.remotes = append(g.remotes, r)
+		g.flashMsg("%s joined over SSH", r.name())
+	case *tcell.EventError:
+		g.dropRemote(r)
+	case *tcell.EventResize:
+		r.view.s.Sync()
+	case *tcell.EventKey:
+		r.note = ""
+		scroll := g.keys.Action(PhaseQuestion, ev.Key(), ev.Rune())
+		switch {
+		case ev.Key() == tcell.KeyCtrlC, ev.Key() == tcell.KeyRune && ev.Rune() == 'q':
+			g.dropRemote(r)
+		case scroll == ActionScrollUp:
+			r.view.scrollClue(-r.view.cluePage)
+		case scroll == ActionScrollDown:
+			r.view.scrollClue(r.view.cluePage)
+		case r.team != nil && (ev.Key() == tcell.KeyEnter || ev.Key() == tcell.KeyRune && ev.Rune() == ' '):
+			i := g.e.TeamIndex(r.team)
+			if i < 0 {
+				r.note = r.team.Name + " is out of the game"
+			} else if err := g.e.Do(engine.Buzz{Team: i}); err != nil {
+				r.note = err.Error()
+			}
+		}
+	}
+}
+
+// takeSeat gives a joining player the team they asked for, unless there is
+// no such team or another player already has it
+func (g *Game) takeSeat(r *remote) error {
+	if r.seat == 0 {
+		return nil
+	}
+	teams := g.e.Teams()
+	if r.seat > len(teams) {
+		return fmt.Errorf("there is no team %d, the game has %d", r.seat, len(teams))
+	}
+	t := teams[r.seat-1]
+	for _, other := range g.remotes {
+		if other.team == t {
+			return fmt.Errorf("%s's seat is already taken", t.Name)
+		}
+	}
+	r.team = t
+	return nil
+}
+
+// name describes a remote player for the host
+func (r *remote) name() string {
+	if r.team == nil {
+		return "a spectator"
+	}
+	return r.team.Name
+}
+
+// dropRemote disconnects a remote player
+func (g *Game) dropRemote(r *remote) {
+	for i := range g.remotes {
+		if g.remotes[i] == r {
+			g.remotes = append(g.remotes[:i], g.remotes[i+1:]...)
+			break
+		}
+	}
+	r.gone = true
+	r.view.s.Fini()
+	g.flashMsg("%s left", r.name())
+}
+
+// dropRemotes disconnects every remote player, when the game ends
+func (g *Game) dropRemotes() {
+	for _, r := range g.remotes {
+		r.gone = true
+		r.view.s.Fini()
+	}
+	g.remotes = nil
+}
+
+// drawRemotes brings every remote player's screen up to date with the game
+func (g *Game) drawRemotes() {
+	for _, r := range g.remotes {
+		v := r.view
+		switch g.e.Phase() {
+		case engine.PhaseSetup:
+			v.phase = PhaseSetupNumTeams
+			v.prompt = "waiting for the host to set up the teams"
+		case engine.PhaseBoard:
+			v.phase = PhaseBoard
+		case engine.PhaseQuestion:
+			v.phase = PhaseQuestion
+		}
+		if q := g.e.Current(); q != r.clue {
+			r.clue = q
+			v.clueScroll = 0
+		}
+		v.clueDeadline = g.clueDeadline
+		v.msg = r.status(g.e)
+		v.draw()
+	}
+}
+
+// status is the remote player's status line
+func (r *remote) status(e *engine.Engine) string {
+	hint := "watching · q to leave"
+	switch {
+	case r.team == nil:
+	case e.Buzzed() == r.team:
+		hint = "you buzzed in first! · q to leave"
+	case e.TeamIndex(r.team) < 0:
+		hint = r.team.Name + " is out of the game · q to leave"
+	default:
+		hint = r.team.Name + " · space to buzz in, q to leave"
+	}
+	if r.note != "" {
+		return r.note + " · " + hint
+	}
+	return hint
+}
+
+// sshTty is a tcell.Tty on an SSH channel. Output is queued and written by
+// its own goroutine so the host never waits on a remote connection.
+type sshTty struct {
+	ch      ssh.Channel
+	in      chan []byte
+	pending []byte        // read from the channel but not yet by tcell
+	done    chan struct{} // closed with the tty
+
+	mu     sync.Mutex
+	drain  chan struct{} // closed to wake up a blocked Read
+	out    chan []byte
+	closed bool
+	bye    string // written once the screen is put away
+	size   tcell.WindowSize
+	resize func()
+}
+
+func newSSHTty(ch ssh.Channel) *sshTty {
+	t := &sshTty{
+		ch:    ch,
+		in:    make(chan []byte),
+		done:  make(chan struct{}),
+		drain: make(chan struct{}),
+		out:   make(chan []byte, sshOutQueue),
+		size:  tcell.WindowSize{Width: 80, Height: 24},
+	}
+	go t.readLoop()
+	go t.writeLoop()
+	return t
+}
+
+func (t *sshTty) readLoop() {
+	defer close(t.in)
+	for {
+		buf := make([]byte, 256)
+		n, err := t.ch.Read(buf)
+		if n > 0 {
+			select {
+			case t.in <- buf[:n]:
+			case <-t.done:
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (t *sshTty) writeLoop() {
+	for data := range t.out {
+		t.ch.Write(data)
+	}
+	t.mu.Lock()
+	bye := t.bye
+	t.mu.Unlock()
+	if bye != "" {
+		fmt.Fprintf(t.ch, "%s\r\n", bye)
+	}
+	t.ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+	t.ch.Close()
+}
+
+// farewell sets a message for the player to read after they are hung up on
+func (t *sshTty) farewell(msg string) {
+	t.mu.Lock()
+	t.bye = msg
+	t.mu.Unlock()
+}
+
+func (t *sshTty) setSize(w, h int) {
+	if w <= 0 || h <= 0 {
+		return
+	}
+	t.mu.Lock()
+	t.size = tcell.WindowSize{Width: w, Height: h}
+	cb := t.resize
+	t.mu.Unlock()
+	if cb != nil {
+		cb()
+	}
+}
+
+func (t *sshTty) Start() error {
+	t.mu.Lock()
+	t.drain = make(chan struct{})
+	t.mu.Unlock()
+	return nil
+}
+
+func (t *sshTty) Stop() error { return nil }
+
+func (t *sshTty) Drain() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	select {
+	case <-t.drain:
+	default:
+		close(t.drain)
+	}
+	return nil
+}
+
+func (t *sshTty) NotifyResize(cb func()) {
+	t.mu.Lock()
+	t.resize = cb
+	t.mu.Unlock()
+}
+
+func (t *sshTty) WindowSize() (tcell.WindowSize, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.size, nil
+}
+
+func (t *sshTty) Read(p []byte) (int, error) {
+	if len(t.pending) == 0 {
+		t.mu.Lock()
+		drain := t.drain
+		t.mu.Unlock()
+		select {
+		case data, ok := <-t.in:
+			if !ok {
+				return 0, io.EOF
+			}
+			t.pending = data
+		case <-drain:
+			return 0, nil
+		}
+	}
+	n := copy(p, t.pending)
+	t.pending = t.pending[n:]
+	return n, nil
+}
+
+// Write queues output for the remote terminal, hanging up on players who
+// have fallen too far behind
+func (t *sshTty) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return 0, io.ErrClosedPipe
+	}
+	select {
+	case t.out <- bytes.Clone(p):
+		return len(p), nil
+	default:
+		t.closeLocked()
+		return 0, errors.New("remote terminal too slow")
+	}
+}
+
+func (t *sshTty) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.closed {
+		t.closeLocked()
+	}
+	return nil
+}
+
+func (t *sshTty) closeLocked() {
+	t.closed = true
+	close(t.out)
+	close(t.done)
+}
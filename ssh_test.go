@@ -0,0 +1,310 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/maristcomputersociety/tuipardy/engine"
+	"golang.org/x/crypto/ssh"
+)
+
+// startTestSSH runs a test game and serves it over SSH on a free local
+// port. allowed are the keys that may take seats; nil lets anyone in to
+// watch.
+func startTestSSH(t *testing.T, allowed []ssh.PublicKey) *testGame {
+	t.Helper()
+	tg := newTestGame(t)
+	dir := t.TempDir()
+	keysPath := ""
+	if allowed != nil {
+		var lines []byte
+		for _, k := range allowed {
+			lines = append(lines, ssh.MarshalAuthorizedKey(k)...)
+		}
+		keysPath = filepath.Join(dir, "authorized_keys")
+		if err := os.WriteFile(keysPath, lines, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv, err := newSSHServer(tg.g, filepath.Join(dir, "host_key"), keysPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	tg.addr = ln.Addr().String()
+	go srv.serve(ln)
+	tg.run(t)
+	return tg
+}
+
+// sshPlayer is an SSH client session on a PTY
+type sshPlayer struct {
+	stdin io.Writer
+
+	mu     sync.Mutex
+	out    strings.Builder // everything the player was sent, without escape sequences or spaces
+	closed chan struct{}   // closed when the session's output ends
+}
+
+// escapes matches the terminal control sequences tcell sends, and spaces
+var escapes = regexp.MustCompile(`\x1b(\[[0-9;?]*[ -/]*[@-~]|\][^\x07\x1b]*(\x07|\x1b\\)|[()][0-9A-Za-z]|[=>78M])|[\x00-\x1f ]`)
+
+// dial joins the game as user, with key when it isn't nil
+func dial(addr, user string, key ed25519.PrivateKey) (*ssh.Client, error) {
+	cfg := &ssh.ClientConfig{
+		User:            user,
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         5 * time.Second,
+	}
+	if key != nil {
+		signer, err := ssh.NewSignerFromKey(key)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Auth = []ssh.AuthMethod{ssh.PublicKeys(signer)}
+	}
+	return ssh.Dial("tcp", addr, cfg)
+}
+
+// join connects as user and starts a shell on an 80x24 PTY
+func join(t *testing.T, addr, user string, key ed25519.PrivateKey) *sshPlayer {
+	t.Helper()
+	client, err := dial(addr, user, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	sess, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sess.RequestPty("xterm", 24, 80, ssh.TerminalModes{}); err != nil {
+		t.Fatal(err)
+	}
+	p := &sshPlayer{closed: make(chan struct{})}
+	if p.stdin, err = sess.StdinPipe(); err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := sess.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sess.Shell(); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		defer close(p.closed)
+		buf := make([]byte, 4096)
+		for {
+			n, err := stdout.Read(buf)
+			p.mu.Lock()
+			p.out.WriteString(escapes.ReplaceAllString(string(buf[:n]), ""))
+			p.mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// waitFor waits until the player has been sent text. Spaces are ignored, as
+// tcell moves the cursor over cells that are already blank.
+func (p *sshPlayer) waitFor(t *testing.T, text string) {
+	t.Helper()
+	text = strings.ReplaceAll(text, " ", "")
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		p.mu.Lock()
+		found := strings.Contains(p.out.String(), text)
+		p.mu.Unlock()
+		if found {
+			return
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	t.Fatalf("%q never shown, got:\n%s", text, p.out.String())
+}
+
+// leave quits with q and waits for the server to hang up, so every key sent
+// before has been handled by the host
+func (p *sshPlayer) leave(t *testing.T) {
+	t.Helper()
+	p.press(t, "q")
+	select {
+	case <-p.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("still connected after q")
+	}
+}
+
+func (p *sshPlayer) press(t *testing.T, keys string) {
+	t.Helper()
+	if _, err := io.WriteString(p.stdin, keys); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testKey(t *testing.T) (ssh.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, priv
+}
+
+func TestSSHSpectatorGetsFrame(t *testing.T) {
+	sg := startTestSSH(t, nil)
+	p := join(t, sg.addr, "anyone", nil)
+	p.waitFor(t, "watching · q to leave")
+	p.waitFor(t, "Red")
+	p.waitFor(t, "Blue")
+	p.leave(t)
+}
+
+func TestSSHBuzz(t *testing.T) {
+	pub, priv := testKey(t)
+	sg := startTestSSH(t, []ssh.PublicKey{pub})
+	p := join(t, sg.addr, "team2", priv)
+	p.waitFor(t, "Blue · space to buzz in")
+	sg.openClue(t)
+
+	p.press(t, " ")
+	ev := sg.waitEvent(t, "the buzz", func(ev engine.Event) bool {
+		_, ok := ev.(engine.BuzzerChanged)
+		return ok
+	})
+	if b := ev.(engine.BuzzerChanged).Team; b == nil || b.Name != "Blue" {
+		t.Fatalf("buzzed in: %v, want Blue", b)
+	}
+	p.waitFor(t, "you buzzed in first!")
+
+	p.press(t, " ")
+	p.waitFor(t, engine.ErrBuzzedIn.Error()+": Blue")
+	p.leave(t)
+	for len(sg.events) > 0 {
+		if ev, ok := (<-sg.events).(engine.BuzzerChanged); ok {
+			t.Errorf("buzzing again changed the buzzer: %v", ev.Team)
+		}
+	}
+}
+
+func TestSSHSeats(t *testing.T) {
+	allowedPub, allowedPriv := testKey(t)
+	_, otherPriv := testKey(t)
+	tests := []struct {
+		name     string
+		allowed  []ssh.PublicKey // nil lets anyone watch
+		user     string
+		key      ed25519.PrivateKey
+		rejected bool
+		want     string // on the player's status line
+	}{
+		{"spectator", nil, "guest", nil, false, "watching · q to leave"},
+		{"seat without allowed keys", nil, "team1", nil, false, "team seats need an allowed key, watching instead"},
+		{"seat with allowed key", []ssh.PublicKey{allowedPub}, "team1", allowedPriv, false, "Red · space to buzz in"},
+		{"unlisted key", []ssh.PublicKey{allowedPub}, "team1", otherPriv, true, ""},
+		{"no key", []ssh.PublicKey{allowedPub}, "team1", nil, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sg := startTestSSH(t, tt.allowed)
+			if tt.rejected {
+				if c, err := dial(sg.addr, tt.user, tt.key); err == nil {
+					c.Close()
+					t.Fatal("connected")
+				}
+				return
+			}
+			p := join(t, sg.addr, tt.user, tt.key)
+			p.waitFor(t, tt.want)
+		})
+	}
+
+	// a downgraded seat can't buzz in
+	sg := startTestSSH(t, nil)
+	p := join(t, sg.addr, "team1", nil)
+	p.waitFor(t, "watching instead")
+	sg.openClue(t)
+	p.press(t, " ")
+	p.leave(t)
+	for len(sg.events) > 0 {
+		if ev, ok := (<-sg.events).(engine.BuzzerChanged); ok {
+			t.Errorf("a spectator buzzed in: %v", ev.Team)
+		}
+	}
+}
+
+func TestSSHSeatFollowsTeam(t *testing.T) {
+	pub, priv := testKey(t)
+	sg := startTestSSH(t, []ssh.PublicKey{pub})
+	p := join(t, sg.addr, "team1", priv)
+	p.waitFor(t, "Red · space to buzz in")
+
+	// the host moves Red below Blue, so Red is team 2 now
+	sg.host.InjectKey(tcell.KeyRune, 't', tcell.ModNone)
+	sg.host.InjectKey(tcell.KeyRune, 'J', tcell.ModNone)
+	sg.host.InjectKey(tcell.KeyEsc, 0, tcell.ModNone)
+	sg.waitEvent(t, "the move", func(ev engine.Event) bool {
+		_, ok := ev.(engine.TeamsChanged)
+		return ok
+	})
+	sg.openClue(t)
+
+	p.press(t, " ")
+	ev := sg.waitEvent(t, "the buzz", func(ev engine.Event) bool {
+		_, ok := ev.(engine.BuzzerChanged)
+		return ok
+	})
+	if b := ev.(engine.BuzzerChanged).Team; b == nil || b.Name != "Red" {
+		t.Fatalf("buzzed in: %v, want Red", b)
+	}
+	p.leave(t)
+}
+
+func TestSSHSeatRejected(t *testing.T) {
+	pub, priv := testKey(t)
+	sg := startTestSSH(t, []ssh.PublicKey{pub})
+	red := join(t, sg.addr, "team1", priv)
+	red.waitFor(t, "Red · space to buzz in")
+
+	tests := []struct {
+		user string
+		want string
+	}{
+		{"team3", "there is no team 3, the game has 2"},
+		{"1", "Red's seat is already taken"},
+	}
+	for _, tt := range tests {
+		p := join(t, sg.addr, tt.user, priv)
+		select {
+		case <-p.closed:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: still connected", tt.user)
+		}
+		p.waitFor(t, tt.want)
+	}
+
+	// the seat is free again once its player leaves
+	red.leave(t)
+	join(t, sg.addr, "team1", priv).waitFor(t, "Red · space to buzz in")
+}
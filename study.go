@@ -0,0 +1,410 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/maristcomputersociety/tuipardy/board"
+)
+
+// Study mode turns board files into a deck of flashcards, shown with the
+// question screen, and schedules them with the SM-2 algorithm: each card has
+// an ease factor and an interval in days, which grow as it is remembered and
+// reset when it is forgotten. Review history is kept in a JSON file.
+
+const (
+	studyDateLayout = "2006-01-02"
+	defaultEase     = 2.5
+	minEase         = 1.3
+)
+
+// study grades, as SM-2 response qualities from 0 to 5
+const (
+	gradeAgain = 1 // forgotten
+	gradeHard  = 3
+	gradeGood  = 4
+	gradeEasy  = 5
+)
+
+// studyKeys maps the rating keys to grades
+var studyKeys = map[rune]int{'1': gradeAgain, '2': gradeHard, '3': gradeGood, '4': gradeEasy}
+
+// cardState is the schedule of one card
+type cardState struct {
+	Ease     float64 `json:"ease"`
+	Interval int     `json:"interval"` // days
+	Reps     int     `json:"reps"`     // reviews in a row remembered
+	Lapses   int     `json:"lapses"`   // times forgotten
+	Due      string  `json:"due"`      // date, YYYY-MM-DD
+	Last     string  `json:"last"`     // date of the last review
+}
+
+// review updates a card's schedule for a review graded 0-5 on day today
+func (c *cardState) review(grade int, today time.Time) {
+	if c.Ease == 0 {
+		c.Ease = defaultEase
+	}
+	if grade < gradeHard {
+		c.Reps = 0
+		c.Interval = 1
+		c.Lapses++
+	} else {
+		switch c.Reps {
+		case 0:
+			c.Interval = 1
+		case 1:
+			c.Interval = 6
+		default:
+			c.Interval = int(math.Round(float64(c.Interval) * c.Ease))
+		}
+		c.Reps++
+	}
+	q := float64(5 - grade)
+	c.Ease = max(minEase, c.Ease+0.1-q*(0.08+q*0.02))
+	c.Last = today.Format(studyDateLayout)
+	c.Due = today.AddDate(0, 0, c.Interval).Format(studyDateLayout)
+}
+
+// studyHistory is the review history file
+type studyHistory struct {
+	Cards map[string]*cardState `json:"cards"` // by cardKey
+}
+
+// StudyHistoryPath returns the default study --history file
+func StudyHistoryPath() string { return userDataPath("study.json") }
+
+func loadStudyHistory(path string) (*studyHistory, error) {
+	h := &studyHistory{Cards: map[string]*cardState{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("study history %s: %w", path, err)
+	}
+	if h.Cards == nil {
+		h.Cards = map[string]*cardState{}
+	}
+	return h, nil
+}
+
+func (h *studyHistory) save(path string) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'))
+}
+
+// cardKey identifies a card across runs by its category, question text and
+// question image (the only "question" of a picture clue), so fixing an
+// answer keeps its history
+func cardKey(q *Question) string {
+	sum := sha256.Sum256([]byte(q.Category + "\n" + q.Q + "\n" + q.ImagePath))
+	return hex.EncodeToString(sum[:8])
+}
+
+// studySession is a run through the cards due today
+type studySession struct {
+	history     *studyHistory
+	historyPath string
+	today       time.Time
+	queue       []*Question
+	relearn     map[*Question]bool // forgotten this session, shown again without rescheduling
+	pos         int
+	shown       bool // the answer side is up
+	reviewed    int
+	forgotten   int
+	saveErr     error
+}
+
+// newStudySession queues the cards due by today, oldest first, then up to
+// newCards cards never studied, in deck order
+func newStudySession(deck []*Question, history *studyHistory, historyPath string, today time.Time, newCards int) *studySession {
+	s := &studySession{history: history, historyPath: historyPath, today: today, relearn: map[*Question]bool{}}
+	date := today.Format(studyDateLayout)
+	var due, fresh []*Question
+	for _, q := range deck {
+		st, ok := history.Cards[cardKey(q)]
+		switch {
+		case !ok:
+			if len(fresh) < newCards {
+				fresh = append(fresh, q)
+			}
+		case st.Due <= date:
+			due = append(due, q)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return history.Cards[cardKey(due[i])].Due < history.Cards[cardKey(due[j])].Due
+	})
+	s.queue = append(due, fresh...)
+	return s
+}
+
+// card returns the card being shown, or nil when the session is over
+func (s *studySession) card() *Question {
+	if s.pos >= len(s.queue) {
+		return nil
+	}
+	return s.queue[s.pos]
+}
+
+// rate grades the card being shown and moves on to the next one. Forgotten
+// cards come back at the end of the session.
+func (s *studySession) rate(grade int) {
+	q := s.card()
+	if grade < gradeHard {
+		s.queue = append(s.queue, q)
+	}
+	if !s.relearn[q] {
+		key := cardKey(q)
+		st := s.history.Cards[key]
+		if st == nil {
+			st = &cardState{}
+			s.history.Cards[key] = st
+		}
+		st.review(grade, s.today)
+		s.reviewed++
+		if grade < gradeHard {
+			s.forgotten++
+		}
+		s.saveErr = s.history.save(s.historyPath)
+	}
+	if grade < gradeHard {
+		s.relearn[q] = true
+	}
+	s.pos++
+	s.shown = false
+}
+
+// summary describes the session and when the next cards are due
+func (s *studySession) summary() []string {
+	lines := []string{
+		"study summary",
+		fmt.Sprintf("%d cards reviewed, %d forgotten", s.reviewed, s.forgotten),
+	}
+	if next := s.nextDue(); next != "" {
+		lines = append(lines, "next review due "+next)
+	}
+	if s.saveErr != nil {
+		lines = append(lines, fmt.Sprintf("couldn't save history: %v", s.saveErr))
+	}
+	return lines
+}
+
+// nextDue returns the date the next card is due after today, or ""
+func (s *studySession) nextDue() string {
+	next := ""
+	for _, st := range s.history.Cards {
+		if st.Due > s.today.Format(studyDateLayout) && (next == "" || st.Due < next) {
+			next = st.Due
+		}
+	}
+	return next
+}
+
+// studyView shows a study session's cards on the question screen, then its
+// summary
+type studyView struct {
+	clueView
+	session *studySession
+	msg     string // status line
+}
+
+// newStudyView sets up a view of a session. cfg must have passed validate.
+func newStudyView(session *studySession, cfg *Config) *studyView {
+	keys, _ := NewKeymap(cfg.Keys)
+	v := &studyView{clueView: newClueView(cfg.Images, keys), session: session}
+	v.showCard()
+	return v
+}
+
+// Run shows the session on s until it is over or the player quits. s is
+// initialized here and finalized on return.
+func (v *studyView) Run(s tcell.Screen) error {
+	if err := s.Init(); err != nil {
+		return err
+	}
+	defer s.Fini()
+	v.setScreen(s)
+	for {
+		v.draw()
+		if ev := s.PollEvent(); ev != nil && v.handleEvent(ev) {
+			return nil
+		}
+	}
+}
+
+func (v *studyView) draw() {
+	s := v.s
+	s.Clear()
+	if q := v.session.card(); q != nil {
+		v.drawClue(q, v.session.shown)
+		drawStatusLine(s, v.msg)
+	} else {
+		drawSummary(s, v.session.summary())
+	}
+	s.Show()
+	v.pt.Flush()
+}
+
+// handleEvent handles one screen event, reporting whether the session is over
+func (v *studyView) handleEvent(ev tcell.Event) bool {
+	switch e := ev.(type) {
+	case *tcell.EventResize:
+		v.s.Sync()
+		v.pt.Invalidate()
+	case *tcell.EventKey:
+		return v.handleKey(e.Key(), e.Rune())
+	}
+	return false
+}
+
+// showCard sets the status line for the card being shown
+func (v *studyView) showCard() {
+	s := v.session
+	v.clueScroll = 0
+	if s.card() == nil {
+		return
+	}
+	left := len(s.queue) - s.pos
+	if s.shown {
+		v.msg = fmt.Sprintf("how well did you know it? 1 again, 2 hard, 3 good, 4 easy (%d left)", left)
+		return
+	}
+	v.msg = fmt.Sprintf("%s to show the answer, %s to stop (%d left)", v.keys.Names(ActionReveal), v.keys.Names(ActionBack), left)
+}
+
+// handleKey flips and rates cards, and leaves on any key once the summary is
+// up
+func (v *studyView) handleKey(key tcell.Key, r rune) bool {
+	s := v.session
+	if key == tcell.KeyCtrlC || s.card() == nil {
+		return true
+	}
+	if grade, ok := studyKeys[r]; ok && key == tcell.KeyRune && s.shown {
+		s.rate(grade)
+		v.showCard()
+		return false
+	}
+	switch v.keys.Action(PhaseQuestion, key, r) {
+	case ActionReveal:
+		s.shown = !s.shown
+		v.showCard()
+	case ActionScrollUp:
+		v.scrollClue(-v.cluePage)
+	case ActionScrollDown:
+		v.scrollClue(v.cluePage)
+	case ActionBack:
+		// stop early; unrated cards stay due
+		s.pos = len(s.queue)
+	}
+	return false
+}
+
+// studyMain runs "tuipardy study", returning the exit code
+func studyMain(args []string) int {
+	fl := flag.NewFlagSet("study", flag.ExitOnError)
+	configPath := fl.String("config", "", "extra config file, applied over the user config file")
+	historyPath := fl.String("history", StudyHistoryPath(), "review history file")
+	newCards := fl.Int("new", 20, "most cards never studied before to add to the session")
+	images := fl.String("images", ImagesAuto, "image support: auto, kitty or none")
+	themeName := fl.String("theme", "classic", "color theme: "+strings.Join(ThemeNames(), ", ")+", or a TOML/JSON theme file")
+	fl.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s study [flags] <board.csv>...\n", os.Args[0])
+		fl.PrintDefaults()
+	}
+	fl.Parse(args)
+	if fl.NArg() < 1 {
+		fl.Usage()
+		return 2
+	}
+	given := map[string]bool{}
+	fl.Visit(func(f *flag.Flag) { given[f.Name] = true })
+
+	cfg, err := LoadConfig(UserConfigPath(), *configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
+		return 1
+	}
+	themeChosen := cfg.themeSet
+	if given["images"] {
+		cfg.Images = *images
+	}
+	if given["theme"] {
+		cfg.Theme = *themeName
+		themeChosen = true
+	}
+	if err := cfg.validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "error in settings: %v\n", err)
+		return 1
+	}
+	if err := applyTheme(cfg, themeChosen); err != nil {
+		fmt.Fprintf(os.Stderr, "error loading theme: %v\n", err)
+		return 1
+	}
+
+	// the deck is every clue of every board, each card once
+	var deck []*Question
+	seen := map[string]bool{}
+	for _, path := range fl.Args() {
+		b, err := board.Load(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error loading board %s: %v\n", path, err)
+			return 1
+		}
+		for _, cat := range b.Categories {
+			for _, q := range cat.Questions {
+				if key := cardKey(q); !seen[key] {
+					seen[key] = true
+					deck = append(deck, q)
+				}
+			}
+		}
+	}
+	history, err := loadStudyHistory(*historyPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading history: %v\n", err)
+		return 1
+	}
+
+	session := newStudySession(deck, history, *historyPath, time.Now(), *newCards)
+	if session.card() == nil {
+		fmt.Println("nothing to study today")
+		if next := session.nextDue(); next != "" {
+			fmt.Println("next review due " + next)
+		}
+		return 0
+	}
+	v := newStudyView(session, cfg)
+	s, err := tcell.NewScreen()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		return 1
+	}
+	if err := v.Run(s); err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		return 1
+	}
+	fmt.Println(strings.Join(session.summary(), "\n"))
+	return 0
+}
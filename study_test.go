@@ -0,0 +1,170 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+var studyToday = time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+func TestCardReview(t *testing.T) {
+	tests := []struct {
+		name         string
+		grades       []int
+		wantInterval int
+		wantReps     int
+		wantLapses   int
+		wantEase     float64
+	}{
+		{"first good", []int{gradeGood}, 1, 1, 0, 2.5},
+		{"second good", []int{gradeGood, gradeGood}, 6, 2, 0, 2.5},
+		{"third good", []int{gradeGood, gradeGood, gradeGood}, 15, 3, 0, 2.5},
+		{"easy raises ease", []int{gradeEasy}, 1, 1, 0, 2.6},
+		{"hard lowers ease", []int{gradeHard}, 1, 1, 0, 2.36},
+		{"forgotten starts over", []int{gradeGood, gradeGood, gradeAgain}, 1, 0, 1, 2.5 - 0.54},
+		{"good after a lapse", []int{gradeGood, gradeGood, gradeAgain, gradeGood}, 1, 1, 1, 2.5 - 0.54},
+		{"ease floor", []int{gradeAgain, gradeAgain, gradeAgain, gradeAgain}, 1, 0, 4, minEase},
+	}
+	for _, tt := range tests {
+		var c cardState
+		for _, g := range tt.grades {
+			c.review(g, studyToday)
+		}
+		if c.Interval != tt.wantInterval || c.Reps != tt.wantReps || c.Lapses != tt.wantLapses {
+			t.Errorf("%s: interval %d reps %d lapses %d, want %d %d %d",
+				tt.name, c.Interval, c.Reps, c.Lapses, tt.wantInterval, tt.wantReps, tt.wantLapses)
+		}
+		if diff := c.Ease - tt.wantEase; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("%s: ease %.4f, want %.4f", tt.name, c.Ease, tt.wantEase)
+		}
+		wantDue := studyToday.AddDate(0, 0, tt.wantInterval).Format(studyDateLayout)
+		if c.Due != wantDue || c.Last != studyToday.Format(studyDateLayout) {
+			t.Errorf("%s: due %s last %s, want due %s", tt.name, c.Due, c.Last, wantDue)
+		}
+	}
+}
+
+func studyDeck(n int) []*Question {
+	deck := make([]*Question, n)
+	for i := range deck {
+		deck[i] = &Question{Category: "Cat", Value: 100, Q: string(rune('a' + i)), A: "x"}
+	}
+	return deck
+}
+
+func TestNewStudySession(t *testing.T) {
+	deck := studyDeck(6) // a-f
+	h := &studyHistory{Cards: map[string]*cardState{
+		cardKey(deck[0]): {Due: "2026-03-09"},
+		cardKey(deck[1]): {Due: "2026-03-11"}, // not due yet
+		cardKey(deck[2]): {Due: "2026-03-01"},
+		cardKey(deck[3]): {Due: "2026-03-10"},
+	}}
+	tests := []struct {
+		newCards int
+		want     string
+	}{
+		{0, "cad"},
+		{1, "cade"},
+		{5, "cadef"},
+	}
+	for _, tt := range tests {
+		s := newStudySession(deck, h, "", studyToday, tt.newCards)
+		var got strings.Builder
+		for _, q := range s.queue {
+			got.WriteString(q.Q)
+		}
+		if got.String() != tt.want {
+			t.Errorf("newCards %d: queue %q, want %q", tt.newCards, got.String(), tt.want)
+		}
+	}
+}
+
+func TestStudySessionRelearn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "study.json")
+	deck := studyDeck(2)
+	h := &studyHistory{Cards: map[string]*cardState{}}
+	s := newStudySession(deck, h, path, studyToday, 10)
+
+	s.rate(gradeAgain) // a comes back at the end
+	s.rate(gradeGood)  // b
+	if q := s.card(); q != deck[0] {
+		t.Fatalf("after forgetting a, card is %v, want a", q)
+	}
+	s.rate(gradeGood) // relearning a doesn't reschedule it
+	if s.card() != nil {
+		t.Fatalf("session not over")
+	}
+	if s.reviewed != 2 || s.forgotten != 1 {
+		t.Errorf("reviewed %d forgotten %d, want 2 1", s.reviewed, s.forgotten)
+	}
+	if st := h.Cards[cardKey(deck[0])]; st.Lapses != 1 || st.Reps != 0 {
+		t.Errorf("a: lapses %d reps %d, want 1 0", st.Lapses, st.Reps)
+	}
+
+	saved, err := loadStudyHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Cards) != 2 {
+		t.Errorf("saved %d cards, want 2", len(saved.Cards))
+	}
+}
+
+func TestStudyView(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Images = ImagesNone
+	deck := studyDeck(1)
+	deck[0].Q, deck[0].A = "the question", "the answer"
+	session := newStudySession(deck, &studyHistory{Cards: map[string]*cardState{}}, filepath.Join(t.TempDir(), "study.json"), studyToday, 10)
+	v := newStudyView(session, cfg)
+
+	s := tcell.NewSimulationScreen("UTF-8")
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Fini()
+	s.SetSize(80, 24)
+	v.setScreen(s)
+
+	screen := func() string {
+		v.draw()
+		cells, w, _ := s.GetContents()
+		var b strings.Builder
+		for i, c := range cells {
+			if i > 0 && i%w == 0 {
+				b.WriteByte('\n')
+			}
+			b.WriteString(string(c.Runes))
+		}
+		return b.String()
+	}
+	key := func(k tcell.Key, r rune) bool {
+		return v.handleEvent(tcell.NewEventKey(k, r, tcell.ModNone))
+	}
+
+	if got := screen(); !strings.Contains(got, "the question") {
+		t.Fatalf("question not shown:\n%s", got)
+	}
+	key(tcell.KeyRune, '3') // ignored before the answer is shown
+	if session.reviewed != 0 {
+		t.Fatalf("rated before the answer was shown")
+	}
+	key(tcell.KeyRune, ' ')
+	if got := screen(); !strings.Contains(got, "the answer") || !strings.Contains(got, "1 again") {
+		t.Fatalf("answer not shown:\n%s", got)
+	}
+	if key(tcell.KeyRune, '3') {
+		t.Fatalf("quit after rating")
+	}
+	if got := screen(); !strings.Contains(got, "study summary") || !strings.Contains(got, "1 cards reviewed") {
+		t.Fatalf("summary not shown:\n%s", got)
+	}
+	if !key(tcell.KeyRune, 'x') {
+		t.Errorf("any key should leave the summary")
+	}
+}
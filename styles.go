@@ -2,17 +2,54 @@ package main
 
 import "github.com/gdamore/tcell/v2"
 
-func styleHeader() tcell.Style {
-	return tcell.StyleDefault.Background(tcell.ColorDarkBlue).Foreground(tcell.ColorWhite)
-}
-func styleCell() tcell.Style {
-	return tcell.StyleDefault.Background(tcell.ColorBlue).Foreground(tcell.ColorYellow)
-}
-func styleDim() tcell.Style  { return tcell.StyleDefault.Foreground(tcell.ColorSilver) }
-func styleTeam() tcell.Style { return tcell.StyleDefault.Foreground(tcell.ColorWhite) }
-func styleStatus() tcell.Style {
-	return tcell.StyleDefault.Background(tcell.ColorSilver).Foreground(tcell.ColorBlack)
+// styles come from the active theme, see theme.go
+
+func styleHeader() tcell.Style   { return theme.Header }
+func styleCell() tcell.Style     { return theme.Cell }
+func styleDim() tcell.Style      { return theme.Dim }
+func styleTeam() tcell.Style     { return theme.Team }
+func styleStatus() tcell.Style   { return theme.Status }
+func stylePrompt() tcell.Style   { return theme.Prompt }
+func styleQuestion() tcell.Style { return theme.Question }
+func styleAnswer() tcell.Style   { return theme.Answer }
+func styleMarker() tcell.Style   { return theme.Marker }
+
+// styleCode is the base style of inline code and code blocks
+func styleCode() tcell.Style { return theme.Code }
+
+// styleToken returns the style for a syntax class in highlighted code
+func styleToken(t tokenClass) tcell.Style {
+	switch t {
+	case tokKeyword:
+		return theme.Keyword
+	case tokBuiltin:
+		return theme.Builtin
+	case tokString:
+		return theme.String
+	case tokNumber:
+		return theme.Number
+	case tokComment:
+		return theme.Comment
+	}
+	return styleCode()
 }
-func styleQuestion() tcell.Style {
-	return tcell.StyleDefault.Background(tcell.ColorBlack).Foreground(tcell.ColorWhite)
+
+// styleSpan applies clue markup attributes on top of a base style. Syntax
+// colors are only used when highlight is set, i.e. the terminal has at least
+// 16 colors to tell them apart.
+func styleSpan(base tcell.Style, sp span, highlight bool) tcell.Style {
+	st := base
+	if sp.attr&spanCode != 0 {
+		st = styleCode()
+		if highlight && sp.tok != tokPlain {
+			st = styleToken(sp.tok)
+		}
+	}
+	if sp.attr&spanBold != 0 {
+		st = st.Bold(true)
+	}
+	if sp.attr&spanItalic != 0 {
+		st = st.Italic(true)
+	}
+	return st
 }
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/maristcomputersociety/tuipardy/engine"
+)
+
+// team manager modes
+const (
+	teamsBrowse = iota
+	teamsRename
+	teamsAdd
+	teamsMerge  // choosing the team to merge the selected one into
+	teamsRemove // confirming a removal
+)
+
+// teamManager is the state of the team management overlay
+type teamManager struct {
+	sel  int
+	mode int
+	buf  string // name being typed
+	from int    // team being merged
+}
+
+func (g *Game) openTeamManager() {
+	g.teamMgr = &teamManager{}
+	g.narrate("Team management. %s", g.teamSummary())
+}
+
+// handleTeamKey handles keys while the team overlay is open
+func (g *Game) handleTeamKey(key tcell.Key, r rune) bool {
+	m := g.teamMgr
+	if key == tcell.KeyCtrlC {
+		return true
+	}
+	switch m.mode {
+	case teamsRename, teamsAdd:
+		g.handleTeamNameKey(key, r)
+	case teamsMerge:
+		switch {
+		case key == tcell.KeyUp || key == tcell.KeyRune && r == 'k':
+			m.sel = max(0, m.sel-1)
+		case key == tcell.KeyDown || key == tcell.KeyRune && r == 'j':
+			m.sel = min(len(g.e.Teams())-1, m.sel+1)
+		case key == tcell.KeyEnter:
+			g.mergeTeams(m.from, m.sel)
+			m.mode = teamsBrowse
+		case key == tcell.KeyEsc:
+			m.sel = m.from
+			m.mode = teamsBrowse
+		}
+	case teamsRemove:
+		if key == tcell.KeyRune && (r == 'y' || r == 'Y') && g.do(engine.RemoveTeam{Team: m.sel}) {
+			m.sel = min(m.sel, len(g.e.Teams())-1)
+		}
+		m.mode = teamsBrowse
+	default:
+		g.handleTeamBrowseKey(key, r)
+	}
+	return false
+}
+
+func (g *Game) handleTeamBrowseKey(key tcell.Key, r rune) {
+	m := g.teamMgr
+	teams := g.e.Teams()
+	rules := g.e.Rules()
+	if key == tcell.KeyEsc || key == tcell.KeyEnter {
+		g.teamMgr = nil
+		g.narrate("Back to board. %s", g.teamSummary())
+		return
+	}
+	switch key {
+	case tcell.KeyUp:
+		m.sel = max(0, m.sel-1)
+		return
+	case tcell.KeyDown:
+		m.sel = min(len(teams)-1, m.sel+1)
+		return
+	}
+	if key != tcell.KeyRune {
+		return
+	}
+	switch r {
+	case 'k':
+		m.sel = max(0, m.sel-1)
+	case 'j':
+		m.sel = min(len(teams)-1, m.sel+1)
+	case 'K':
+		if m.sel > 0 && g.do(engine.MoveTeam{Team: m.sel, By: -1}) {
+			m.sel--
+		}
+	case 'J':
+		if m.sel < len(teams)-1 && g.do(engine.MoveTeam{Team: m.sel, By: +1}) {
+			m.sel++
+		}
+	case 'r':
+		m.mode = teamsRename
+		m.buf = teams[m.sel].Name
+	case 'a':
+		if len(teams) >= rules.MaxTeams {
+			g.flashMsg("already %d teams, the most allowed", rules.MaxTeams)
+			return
+		}
+		m.mode = teamsAdd
+		m.buf = ""
+	case 'd':
+		if len(teams) <= rules.MinTeams {
+			g.flashMsg("need at least %d teams", rules.MinTeams)
+			return
+		}
+		m.mode = teamsRemove
+		g.flashMsg("remove %s (score %d)? press y to confirm.", teams[m.sel].Name, teams[m.sel].Score)
+	case 'm':
+		if len(teams) <= rules.MinTeams {
+			g.flashMsg("need at least %d teams", rules.MinTeams)
+			return
+		}
+		m.mode = teamsMerge
+		m.from = m.sel
+		m.sel = (m.sel + 1) % len(teams)
+		g.flashMsg("merge %s into which team? arrows to choose, enter to merge.", teams[m.from].Name)
+	case 'u':
+		g.do(engine.Undo{})
+		m.sel = min(m.sel, len(g.e.Teams())-1)
+	}
+}
+
+// handleTeamNameKey edits the name being typed for a rename or a new team
+func (g *Game) handleTeamNameKey(key tcell.Key, r rune) {
+	m := g.teamMgr
+	switch key {
+	case tcell.KeyEsc:
+		m.mode = teamsBrowse
+	case tcell.KeyEnter:
+		name := trimSpaces(m.buf)
+		if name == "" {
+			return
+		}
+		if m.mode == teamsAdd {
+			if g.do(engine.AddTeam{Name: name}) {
+				m.sel = len(g.e.Teams()) - 1
+			}
+		} else {
+			g.do(engine.RenameTeam{Team: m.sel, Name: name})
+		}
+		m.mode = teamsBrowse
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		m.buf = dropLastCluster(m.buf)
+	case tcell.KeyRune:
+		m.buf += string(r)
+	}
+}
+
+// mergeTeams merges team from into team into, keeping into selected
+func (g *Game) mergeTeams(from, into int) {
+	if from == into {
+		return
+	}
+	dst := g.e.Teams()[into]
+	g.do(engine.MergeTeams{From: from, Into: into})
+	g.teamMgr.sel = max(0, g.e.TeamIndex(dst))
+}
+
+// drawTeamManager draws the team overlay centered over the board
+func (g *Game) drawTeamManager() {
+	s := g.s
+	w, h := s.Size()
+	m := g.teamMgr
+
+	footer := " r rename  a add  d remove  m merge  J/K move  u undo  esc close "
+	switch m.mode {
+	case teamsRename, teamsAdd:
+		footer = " enter to save, esc to cancel "
+	case teamsMerge:
+		footer = " enter to merge, esc to cancel "
+	case teamsRemove:
+		footer = " y to remove, any other key to cancel "
+	}
+	teams := g.e.Teams()
+	rows := len(teams)
+	if m.mode == teamsAdd {
+		rows++
+	}
+	boxW := min(w, max(textWidth(footer)+4, 40))
+	boxH := min(h-StatusBarHeight, rows+4)
+	x, y := (w-boxW)/2, (h-StatusBarHeight-boxH)/2
+
+	g.pt.Drop()
+	fillBox(s, x, y, boxW, boxH, stylePrompt())
+	drawBox(s, x, y, boxW, boxH, stylePrompt())
+	drawCenteredText(s, x, y, boxW, 1, stylePrompt(), " teams ")
+	drawCenteredText(s, x, y+boxH-1, boxW, 1, stylePrompt().Bold(false), truncateText(footer, boxW-2))
+
+	for i := 0; i < rows && y+2+i < y+boxH-2; i++ {
+		st := stylePrompt().Bold(false)
+		var name, score string
+		switch {
+		case i == len(teams):
+			name, score = m.buf+"▏", "new"
+		case i == m.sel && m.mode == teamsRename:
+			name, score = m.buf+"▏", fmt.Sprint(teams[i].Score)
+		default:
+			name, score = teams[i].Name, fmt.Sprint(teams[i].Score)
+		}
+		marker := "  "
+		if i == m.from && m.mode == teamsMerge {
+			marker = "→ "
+		}
+		if (i == m.sel && m.mode != teamsAdd) || i == len(teams) {
+			st = st.Reverse(true)
+		}
+		label := fmt.Sprintf("%s%d) %s", marker, i+1, name)
+		inner := boxW - 4
+		line := truncateText(label, inner-textWidth(score)-1)
+		pad := max(1, inner-textWidth(line)-textWidth(score))
+		drawText(s, x+2, y+2+i, st, fmt.Sprintf("%s%*s%s", line, pad, "", score))
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Theme holds the style of every element the game draws
+type Theme struct {
+	Name     string
+	Header   tcell.Style // category headers
+	Cell     tcell.Style // question cells on the board
+	Dim      tcell.Style // separators, card borders, notices
+	Team     tcell.Style // team names and scores
+	Status   tcell.Style // status bar
+	Prompt   tcell.Style // setup prompts
+	Question tcell.Style // question screen and clue text
+	Answer   tcell.Style // revealed answer text
+	Marker   tcell.Style // "more" markers on scrolling clues
+	Code     tcell.Style // code spans and blocks
+	Keyword  tcell.Style // syntax classes in highlighted code
+	Builtin  tcell.Style
+	String   tcell.Style
+	Number   tcell.Style
+	Comment  tcell.Style
+}
+
+// roles maps the style names used in theme files to the theme's fields
+func (t *Theme) roles() map[string]*tcell.Style {
+	return map[string]*tcell.Style{
+		"header":   &t.Header,
+		"cell":     &t.Cell,
+		"dim":      &t.Dim,
+		"team":     &t.Team,
+		"status":   &t.Status,
+		"prompt":   &t.Prompt,
+		"question": &t.Question,
+		"answer":   &t.Answer,
+		"marker":   &t.Marker,
+		"code":     &t.Code,
+		"keyword":  &t.Keyword,
+		"builtin":  &t.Builtin,
+		"string":   &t.String,
+		"number":   &t.Number,
+		"comment":  &t.Comment,
+	}
+}
+
+func style(fg, bg tcell.Color) tcell.Style {
+	return tcell.StyleDefault.Foreground(fg).Background(bg)
+}
+
+func themeClassic() *Theme {
+	code := tcell.Color236
+	return &Theme{
+		Name:     "classic",
+		Header:   style(tcell.ColorWhite, tcell.ColorDarkBlue),
+		Cell:     style(tcell.ColorYellow, tcell.ColorBlue),
+		Dim:      style(tcell.ColorSilver, tcell.ColorDefault),
+		Team:     style(tcell.ColorWhite, tcell.ColorDefault),
+		Status:   style(tcell.ColorBlack, tcell.ColorSilver),
+		Prompt:   style(tcell.ColorDefault, tcell.ColorDefault).Bold(true),
+		Question: style(tcell.ColorWhite, tcell.ColorBlack),
+		Answer:   style(tcell.ColorLightGreen, tcell.ColorBlack),
+		Marker:   style(tcell.ColorSilver, tcell.ColorBlack),
+		Code:     style(tcell.ColorWhite, code),
+		Keyword:  style(tcell.ColorFuchsia, code).Bold(true),
+		Builtin:  style(tcell.ColorAqua, code),
+		String:   style(tcell.ColorOrange, code),
+		Number:   style(tcell.ColorYellow, code),
+		Comment:  style(tcell.ColorGray, code).Italic(true),
+	}
+}
+
+func themeHighContrast() *Theme {
+	black, white, yellow := tcell.ColorBlack, tcell.ColorWhite, tcell.ColorYellow
+	return &Theme{
+		Name:     "high-contrast",
+		Header:   style(black, white).Bold(true),
+		Cell:     style(yellow, black).Bold(true),
+		Dim:      style(white, black),
+		Team:     style(white, black).Bold(true),
+		Status:   style(black, yellow),
+		Prompt:   style(white, black).Bold(true),
+		Question: style(white, black),
+		Answer:   style(yellow, black).Bold(true).Underline(true),
+		Marker:   style(yellow, black),
+		Code:     style(white, black).Underline(true),
+		Keyword:  style(yellow, black).Bold(true),
+		Builtin:  style(tcell.ColorAqua, black),
+		String:   style(tcell.ColorLime, black),
+		Number:   style(tcell.ColorFuchsia, black),
+		Comment:  style(white, black).Italic(true),
+	}
+}
+
+func themeLight() *Theme {
+	paper, ink, code := tcell.ColorWhite, tcell.ColorBlack, tcell.Color254
+	return &Theme{
+		Name:     "light",
+		Header:   style(paper, tcell.ColorNavy),
+		Cell:     style(tcell.ColorNavy, tcell.ColorLightSkyBlue),
+		Dim:      style(tcell.ColorGray, tcell.ColorDefault),
+		Team:     style(ink, tcell.ColorDefault),
+		Status:   style(paper, tcell.ColorNavy),
+		Prompt:   style(ink, tcell.ColorDefault).Bold(true),
+		Question: style(ink, paper),
+		Answer:   style(tcell.ColorDarkGreen, paper),
+		Marker:   style(tcell.ColorGray, paper),
+		Code:     style(ink, code),
+		Keyword:  style(tcell.ColorPurple, code).Bold(true),
+		Builtin:  style(tcell.ColorTeal, code),
+		String:   style(tcell.ColorMaroon, code),
+		Number:   style(tcell.ColorOlive, code),
+		Comment:  style(tcell.ColorGray, code).Italic(true),
+	}
+}
+
+// themeMonochrome uses attributes only, leaving colors to the terminal
+func themeMonochrome() *Theme {
+	plain := tcell.StyleDefault
+	return &Theme{
+		Name:     "monochrome",
+		Header:   plain.Reverse(true).Bold(true),
+		Cell:     plain,
+		Dim:      plain.Dim(true),
+		Team:     plain,
+		Status:   plain.Reverse(true),
+		Prompt:   plain.Bold(true),
+		Question: plain,
+		Answer:   plain.Bold(true).Underline(true),
+		Marker:   plain.Dim(true),
+		Code:     plain,
+		Keyword:  plain.Bold(true),
+		Builtin:  plain,
+		String:   plain.Underline(true),
+		Number:   plain,
+		Comment:  plain.Italic(true),
+	}
+}
+
+// themeClub uses the Marist red and white
+func themeClub() *Theme {
+	red := tcell.NewHexColor(0xc8102e)
+	t := themeClassic()
+	t.Name = "club"
+	t.Header = style(tcell.ColorWhite, red).Bold(true)
+	t.Cell = style(tcell.ColorWhite, tcell.NewHexColor(0x8b0b20))
+	t.Status = style(tcell.ColorWhite, red)
+	t.Answer = style(tcell.NewHexColor(0xff8a9a), tcell.ColorBlack)
+	return t
+}
+
+// builtinThemes are the themes selectable by name
+var builtinThemes = map[string]func() *Theme{
+	"classic":       themeClassic,
+	"high-contrast": themeHighContrast,
+	"light":         themeLight,
+	"monochrome":    themeMonochrome,
+	"club":          themeClub,
+}
+
+// ThemeNames returns the names of the built-in themes
+func ThemeNames() []string {
+	names := make([]string, 0, len(builtinThemes))
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// theme is the active theme; every style* helper reads from it
+var theme = themeClassic()
+
+// SetTheme makes t the active theme
+func SetTheme(t *Theme) { theme = t }
+
+// StyleSpec is a style as written in a theme file. Colors are W3C names
+// ("navy"), hex ("#c8102e") or 256-color palette numbers ("236").
+type StyleSpec struct {
+	Fg        string `toml:"fg" json:"fg"`
+	Bg        string `toml:"bg" json:"bg"`
+	Bold      bool   `toml:"bold" json:"bold"`
+	Italic    bool   `toml:"italic" json:"italic"`
+	Underline bool   `toml:"underline" json:"underline"`
+	Reverse   bool   `toml:"reverse" json:"reverse"`
+	Dim       bool   `toml:"dim" json:"dim"`
+}
+
+// themeFile is the on-disk form of a theme: a built-in base plus the styles
+// it overrides
+type themeFile struct {
+	Name   string               `toml:"name" json:"name"`
+	Base   string               `toml:"base" json:"base"`
+	Styles map[string]StyleSpec `toml:"styles" json:"styles"`
+}
+
+// LoadTheme returns the built-in theme called name, or else loads name as a
+// TOML or JSON theme file
+func LoadTheme(name string) (*Theme, error) {
+	if mk, ok := builtinThemes[name]; ok {
+		return mk(), nil
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown theme %q (built-in themes: %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+
+	var tf themeFile
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		err = json.Unmarshal(data, &tf)
+	} else {
+		var md toml.MetaData
+		md, err = toml.Decode(string(data), &tf)
+		if err == nil && len(md.Undecoded()) > 0 {
+			err = fmt.Errorf("unknown key %q", md.Undecoded()[0].String())
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("theme %s: %w", name, err)
+	}
+	return tf.build()
+}
+
+func (tf themeFile) build() (*Theme, error) {
+	base := tf.Base
+	if base == "" {
+		base = "classic"
+	}
+	mk, ok := builtinThemes[base]
+	if !ok {
+		return nil, fmt.Errorf("unknown base theme %q", base)
+	}
+	t := mk()
+	if tf.Name != "" {
+		t.Name = tf.Name
+	}
+
+	roles := t.roles()
+	for role, spec := range tf.Styles {
+		dst, ok := roles[role]
+		if !ok {
+			return nil, fmt.Errorf("unknown style %q", role)
+		}
+		st, err := spec.style()
+		if err != nil {
+			return nil, fmt.Errorf("style %q: %w", role, err)
+		}
+		*dst = st
+	}
+	return t, nil
+}
+
+func (sp StyleSpec) style() (tcell.Style, error) {
+	fg, err := parseColor(sp.Fg)
+	if err != nil {
+		return tcell.StyleDefault, err
+	}
+	bg, err := parseColor(sp.Bg)
+	if err != nil {
+		return tcell.StyleDefault, err
+	}
+	return style(fg, bg).
+		Bold(sp.Bold).
+		Italic(sp.Italic).
+		Underline(sp.Underline).
+		Reverse(sp.Reverse).
+		Dim(sp.Dim), nil
+}
+
+func parseColor(name string) (tcell.Color, error) {
+	if name == "" || strings.EqualFold(name, "default") {
+		return tcell.ColorDefault, nil
+	}
+	if n, err := strconv.Atoi(name); err == nil {
+		if n < 0 || n > 255 {
+			return tcell.ColorDefault, fmt.Errorf("palette color %d out of range", n)
+		}
+		return tcell.PaletteColor(n), nil
+	}
+	c := tcell.GetColor(strings.ToLower(name))
+	if c == tcell.ColorDefault {
+		return c, fmt.Errorf("bad color %q", name)
+	}
+	return c, nil
+}
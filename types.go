@@ -1,53 +1,39 @@
 package main
 
-type Question struct {
-	Category  string
-	Value     int
-	Q         string
-	A         string
-	ImagePath string // optional
-	Picked    bool
-}
-
-type Category struct {
-	Name      string
-	Questions []*Question
-}
-
-type Board struct {
-	Categories []*Category // len == 6
-}
+import (
+	"github.com/maristcomputersociety/tuipardy/board"
+	"github.com/maristcomputersociety/tuipardy/engine"
+)
 
-type Team struct {
-	Name  string
-	Score int
-}
+// the board model lives in package board, the game rules in package engine
+type (
+	Question = board.Question
+	Category = board.Category
+	Board    = board.Board
+	Team     = engine.Team
+)
 
-// game phases
+// screens, board and question following the engine's phase
 const (
 	PhaseSetupNumTeams = iota
 	PhaseSetupTeamNames
 	PhaseBoard
 	PhaseQuestion
+	PhaseSummary // end of a practice game
 )
 
 // UI constants
 const (
 	QuestionAreaY      = 7
-	CategoryHeight     = 3
-	CellHeight         = 7
+	MinCategoryHeight  = 3 // header with one line of text
+	MaxCategoryLines   = 3 // longer category names are cut off
+	MinCellHeight      = 3
 	MinColumnWidth     = 12
 	TeamBaselineOffset = 1
+	TeamCardHeight     = 4                 // border, name, score, border
+	BigTeamCardHeight  = 3 + BigFontHeight // room for the score in the big font
 	StatusBarHeight    = 1
 	ImageSplitRatio    = 2  // image takes 1/ImageSplitRatio of screen width (for vertical split)
 	ImageHeightRatio   = 65 // image takes ImageHeightRatio% of question area height (for horizontal split)
 	ImageTextPadding   = 2  // padding between image and text areas
 )
-
-// game configuration
-const (
-	MinTeams             = 2
-	MaxTeams             = 8
-	QuestionsPerCategory = 3
-	ExpectedCategories   = 6
-)
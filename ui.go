@@ -3,8 +3,10 @@ package main
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/maristcomputersociety/tuipardy/board"
 )
 
 func (g *Game) draw() {
@@ -14,197 +16,184 @@ func (g *Game) draw() {
 
 	switch g.phase {
 	case PhaseSetupNumTeams, PhaseSetupTeamNames:
-		drawCenteredText(s, 0, 0, w, h/2, tcell.StyleDefault.Bold(true), g.prompt+g.inputBuf)
+		drawCenteredText(s, 0, 0, w, h/2, stylePrompt(), g.prompt+g.inputBuf)
 		if g.msg != "" {
-			drawCenteredText(s, 0, h/2, w, h/2, tcell.StyleDefault, g.msg)
+			drawCenteredText(s, 0, h/2, w, h/2, stylePrompt().Bold(false), g.msg)
 		}
 	case PhaseBoard:
 		g.drawBoard()
 		g.drawTeams()
 		g.drawStatus()
 	case PhaseQuestion:
-		g.drawQuestion()
+		g.drawClue(g.e.Current(), g.e.AnswerShown())
 		g.drawStatus()
+	case PhaseSummary:
+		drawSummary(s, g.practiceSummary())
+	}
+	if g.teamMgr != nil {
+		g.drawTeamManager()
+	}
+	if g.showHelp {
+		g.drawHelp()
 	}
 
 	s.Show()
 
-	// hacky solution, render images after tcell has rendered the screen
-	if g.phase == PhaseQuestion && g.curQ != nil && g.curQ.ImagePath != "" && g.imageSupported {
-		g.renderImageAfterShow()
-	}
-
-	// hacky solution, put text to stdout for kitty text sizing
-	if g.phase == PhaseQuestion && g.curQ != nil && g.textToRender != "" {
-		g.renderTextAfterShow()
-	}
+	// images and scaled text go out after tcell has rendered the screen
+	g.pt.Flush()
 }
 
 func (g *Game) drawBoard() {
 	s := g.s
-	w, _ := s.Size()
-	cols := len(g.board.Categories)
-	colW := max(MinColumnWidth, w/cols)
-	categoryHeight := CategoryHeight
-	boxHeight := CellHeight
+	w, h := s.Size()
+	l := g.layoutBoard(w, h)
+	if l.tooSmall() {
+		g.drawTooSmall(s, l)
+		return
+	}
 
-	for c, cat := range g.board.Categories {
-		g.drawCategoryHeader(s, c, colW, categoryHeight, cat)
-		g.drawCategoryCells(s, c, colW, categoryHeight, boxHeight, cat)
+	for c, cat := range g.e.Board().Categories {
+		g.drawCategoryHeader(s, l, c)
+		g.drawCategoryCells(s, l, c, cat)
 	}
 }
 
+// drawTooSmall replaces the board with a notice when the terminal can't fit it
+func (g *Game) drawTooSmall(s tcell.Screen, l boardLayout) {
+	msg := fmt.Sprintf("terminal too small\nneed %dx%d, have %dx%d", l.minW, l.minH, l.w, l.h)
+	drawCenteredText(s, 0, 0, l.w, l.h-StatusBarHeight, styleDim(), msg)
+}
+
 // drawCategoryHeader renders a single category header
-func (g *Game) drawCategoryHeader(s tcell.Screen, col, colW, categoryHeight int, cat *Category) {
-	x0 := col * colW
-	fillBox(s, x0, 0, colW, categoryHeight, styleHeader())
-	drawBox(s, x0, 0, colW, categoryHeight, styleHeader())
-	drawCenteredText(s, x0, 1, colW, 1, styleHeader().Bold(true), strings.ToUpper(cat.Name))
+func (g *Game) drawCategoryHeader(s tcell.Screen, l boardLayout, col int) {
+	x0, colW := l.colX(col), l.colWidth(col)
+	fillBox(s, x0, 0, colW, l.headerH, styleHeader())
+	drawBox(s, x0, 0, colW, l.headerH, styleHeader())
+
+	lines := l.headers[col]
+	if len(lines) > l.headerH-2 {
+		lines = lines[:l.headerH-2]
+	}
+	drawCenteredText(s, x0, 1, colW, l.headerH-2, styleHeader().Bold(true), strings.Join(lines, "\n"))
 }
 
 // drawCategoryCells renders all question cells for a category
-func (g *Game) drawCategoryCells(s tcell.Screen, col, colW, categoryHeight, boxHeight int, cat *Category) {
-	for r := range QuestionsPerCategory {
-		g.drawQuestionCell(s, col, r, colW, categoryHeight, boxHeight, cat.Questions[r])
+func (g *Game) drawCategoryCells(s tcell.Screen, l boardLayout, col int, cat *Category) {
+	for r := range board.QuestionsPerCategory {
+		g.drawQuestionCell(s, l, col, r, cat.Questions[r])
 	}
 }
 
-// drawQuestionCell renders a single question cell
-func (g *Game) drawQuestionCell(s tcell.Screen, col, row, colW, categoryHeight, boxHeight int, q *Question) {
-	x0 := col * colW
-	y := categoryHeight + row*boxHeight
+// drawQuestionCell renders a single question cell. Taken cells are hatched
+// and the cursor is marked with arrows, so neither relies on color alone.
+func (g *Game) drawQuestionCell(s tcell.Screen, l boardLayout, col, row int, q *Question) {
+	x0, colW := l.colX(col), l.colWidth(col)
+	y := l.cellY(row)
+	midY := y + l.cellH/2
 
-	fillBox(s, x0, y, colW, boxHeight, styleCell())
-	drawBox(s, x0, y, colW, boxHeight, styleCell())
+	fillBox(s, x0, y, colW, l.cellH, styleCell())
+	drawBox(s, x0, y, colW, l.cellH, styleCell())
 
 	label := fmt.Sprintf("$%d", q.Value)
 	if q.Picked {
-		label = "—"
+		for j := 1; j < l.cellH-1; j++ {
+			for i := 1; i < colW-1; i++ {
+				setCell(s, x0+i, y+j, '░', styleCell().Dim(true))
+			}
+		}
+		label = " ✓ "
 	}
 
 	st := styleCell().Bold(true)
-	if col == g.cursorCol && row == g.cursorRow && g.phase == PhaseBoard {
+	cursorCol, cursorRow := g.e.Cursor()
+	selected := col == cursorCol && row == cursorRow && g.phase == PhaseBoard
+	if selected {
 		st = st.Reverse(true)
+		setCell(s, x0+1, midY, '▶', styleCell().Bold(true))
+		setCell(s, x0+colW-2, midY, '◀', styleCell().Bold(true))
 	}
 
-	drawCenteredText(s, x0, y+3, colW, 1, st, label)
+	// big digits when the cell has room for them, inverting the whole cell
+	// when selected so the cursor stays obvious
+	if !q.Picked && fitsBig(label, colW-4, l.cellH-2) {
+		if selected {
+			fillBox(s, x0+2, y+1, colW-4, l.cellH-2, st)
+		}
+		drawCenteredBigText(s, x0+2, y+1, colW-4, l.cellH-2, st, label)
+		return
+	}
+
+	drawCenteredText(s, x0, midY, colW, 1, st, label)
 }
 
+// drawTeams renders a score card per team along the bottom of the board
 func (g *Game) drawTeams() {
 	s := g.s
 	w, h := s.Size()
-	baseline := CategoryHeight + QuestionsPerCategory*CellHeight + TeamBaselineOffset
-	if baseline >= h {
-		baseline = h - 3
-	}
-	for x := 0; x < w; x++ {
-		setCell(s, x, baseline, '─', styleDim())
+	l := g.layoutBoard(w, h)
+	teams := g.e.Teams()
+	if l.tooSmall() || len(teams) == 0 {
+		return
 	}
-	y := baseline + 1
-	for i, t := range g.teams {
-		line := fmt.Sprintf("%d) %s — %d", i+1, t.Name, t.Score)
-		drawText(s, 1, y+i, styleTeam(), line)
+	n := len(teams)
+	for i, t := range teams {
+		x0 := i * w / n
+		cardW := (i+1)*w/n - x0
+		g.drawTeamCard(s, x0, l.teamsY, cardW, l.cardH, i, t)
 	}
 }
 
-func (g *Game) drawStatus() {
-	s := g.s
-	w, h := s.Size()
-	status := g.msg
-	if g.phase == PhaseBoard && g.inputBuf != "" {
-		status = fmt.Sprintf("score command: %s  (press enter to apply)", g.inputBuf)
+// drawTeamCard renders one team's name and score, the score in the big font
+// when the card is tall and wide enough
+func (g *Game) drawTeamCard(s tcell.Screen, x, y, w, h, idx int, t *Team) {
+	st := styleTeam()
+	border := styleDim()
+	label := fmt.Sprintf("%d) %s", idx+1, t.Name)
+	if t == g.e.Control() {
+		// marked with arrows as well as color so it reads without color
+		border = styleMarker()
+		label = "▶ " + label + " ◀"
+	}
+	drawBox(s, x, y, w, h, border)
+
+	name := truncateText(label, w-2)
+	drawCenteredText(s, x, y+1, w, 1, st.Bold(true), name)
+
+	score := fmt.Sprintf("%d", t.Score)
+	if fitsBig(score, w-2, h-3) {
+		drawCenteredBigText(s, x+1, y+2, w-2, h-3, st, score)
+		return
 	}
-	pad := strings.Repeat(" ", max(0, w-len(status)-1))
-	drawText(s, 0, h-StatusBarHeight, styleStatus(), status+pad)
+	drawCenteredText(s, x, y+2, w, 1, st, truncateText(score, w-2))
 }
 
-// drawQuestion renders the question/answer screen
-func (g *Game) drawQuestion() {
+func (g *Game) drawStatus() {
 	s := g.s
-	w, h := s.Size()
-	if g.curQ == nil {
-		return
+	status := g.msg
+	if c := g.e.Control(); g.phase == PhaseBoard && c != nil {
+		status = fmt.Sprintf("%s picks · %s", c.Name, status)
 	}
-
-	g.drawQuestionBackground(s, w, h)
-	g.drawQuestionTitle(s, w)
-	g.drawQuestionSeparator(s, w)
-	g.drawQuestionContent(s, w, h)
-}
-
-// drawQuestionBackground fills and draws the background box for the question screen
-func (g *Game) drawQuestionBackground(s tcell.Screen, w, h int) {
-	fillBox(s, 0, 0, w, h-StatusBarHeight, styleQuestion())
-	drawBox(s, 0, 0, w, h-StatusBarHeight, styleQuestion())
-}
-
-// drawQuestionTitle renders the category and value at the top of the question screen
-func (g *Game) drawQuestionTitle(s tcell.Screen, w int) {
-	title := fmt.Sprintf("%s — $%d", g.curQ.Category, g.curQ.Value)
-	drawCenteredText(s, 0, 3, w, 1, styleQuestion().Bold(true), title)
-}
-
-// drawQuestionSeparator draws a horizontal separator line below the title
-func (g *Game) drawQuestionSeparator(s tcell.Screen, w int) {
-	separatorY := 5
-	for x := w / 4; x < 3*w/4; x++ {
-		setCell(s, x, separatorY, '─', styleQuestion())
+	if b := g.e.Buzzed(); g.phase == PhaseQuestion && b != nil {
+		status = fmt.Sprintf("%s buzzed in · %s", b.Name, status)
 	}
-}
-
-// drawQuestionContent renders the main question/answer text with optional image
-func (g *Game) drawQuestionContent(s tcell.Screen, w, h int) {
-	questionAreaY := QuestionAreaY
-	questionAreaH := h - questionAreaY - 3
-
-	textToShow, textStyle := g.curQ.Q, styleQuestion().Bold(true)
-	if g.showAnswer {
-		textToShow, textStyle = g.curQ.A, styleQuestion().Bold(true).Foreground(tcell.ColorLightGreen)
+	if p := g.practice; p != nil && g.phase == PhaseQuestion && !p.judged {
+		status = fmt.Sprintf("your response: %s▏ (enter to answer, %s to pass)", p.input, g.keyNames(ActionBack))
 	}
-
-	if g.curQ.ImagePath != "" && g.imageSupported && g.imageRenderer != nil {
-		// Use horizontal split: top 65% for image, bottom 35% for text
-		g.drawQuestionWithImage(s, w, questionAreaY, questionAreaH, textToShow, textStyle)
-	} else {
-		// Full screen for text when no image
-		g.drawQuestionFullWidth(s, w, questionAreaY, questionAreaH, textToShow, textStyle)
+	if g.typingCmd {
+		status = fmt.Sprintf("score command: %s▏ (enter to apply, %s to cancel)", g.inputBuf, g.keyNames(ActionCancel))
+		if g.cmdErr != nil {
+			status = fmt.Sprintf("score command: %s▏ ✗ %v", g.inputBuf, g.cmdErr)
+		}
 	}
-}
-
-// drawQuestionWithImage renders question text below an image in a horizontal split
-func (g *Game) drawQuestionWithImage(s tcell.Screen, w, questionAreaY, questionAreaH int, textToShow string, textStyle tcell.Style) {
-	// split horizontally: top 65% for image, bottom 35% for text
-	imageHeight := questionAreaH * ImageHeightRatio / 100
-	textHeight := questionAreaH - imageHeight
-	textY := questionAreaY + imageHeight
-
-	textPadding := ImageTextPadding
-	adjustedTextY := textY + textPadding
-	adjustedTextHeight := textHeight - textPadding
-
-	if adjustedTextHeight < 1 {
-		adjustedTextHeight = 1
-		adjustedTextY = textY
+	if left, ok := g.clueTimeLeft(); ok {
+		status = fmt.Sprintf("⏱ %ds  %s", int(left.Round(time.Second)/time.Second), status)
 	}
-
-	clearTextArea(s, 0, adjustedTextY, w, adjustedTextHeight, textStyle.Background(tcell.ColorBlack))
-
-	g.textToRender = textToShow
-	g.textStyle = textStyle
-	g.textAreaX = 0
-	g.textAreaY = adjustedTextY
-	g.textAreaW = w
-	g.textAreaH = adjustedTextHeight
+	drawStatusLine(s, status)
 }
 
-// drawQuestionFullWidth renders question text across the full width of the screen
-func (g *Game) drawQuestionFullWidth(s tcell.Screen, w, questionAreaY, questionAreaH int, textToShow string, textStyle tcell.Style) {
-	clearTextArea(s, 0, questionAreaY, w, questionAreaH, textStyle.Background(tcell.ColorBlack))
-
-	g.textToRender = textToShow
-	g.textStyle = textStyle
-	g.textAreaX = 0
-	g.textAreaY = questionAreaY
-	g.textAreaW = w
-	g.textAreaH = questionAreaH
+// drawStatusLine draws the status bar along the bottom of the screen
+func drawStatusLine(s tcell.Screen, status string) {
+	w, h := s.Size()
+	pad := strings.Repeat(" ", max(0, w-textWidth(status)-1))
+	drawText(s, 0, h-StatusBarHeight, styleStatus(), status+pad)
 }
@@ -4,19 +4,73 @@ import (
 	"strings"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/uniseg"
 )
 
-func drawText(s tcell.Screen, x, y int, st tcell.Style, text string) {
-	for i, ch := range text {
-		s.SetContent(x+i, y, ch, nil, st)
+// drawText draws text one grapheme cluster per cell, advancing by each
+// cluster's display width so combining marks and wide glyphs line up.
+// It returns the x position just past the text.
+func drawText(s tcell.Screen, x, y int, st tcell.Style, text string) int {
+	state := -1
+	for text != "" {
+		var cluster string
+		var width int
+		cluster, text, width, state = uniseg.FirstGraphemeClusterInString(text, state)
+		if width == 0 {
+			continue
+		}
+		rs := []rune(cluster)
+		s.SetContent(x, y, rs[0], rs[1:], st)
+		x += width
+	}
+	return x
+}
+
+// textWidth returns the number of cells text takes up on screen
+func textWidth(text string) int { return uniseg.StringWidth(text) }
+
+// cutText splits text after as many grapheme clusters as fit in maxWidth
+// cells. At least one cluster is taken so callers always make progress.
+func cutText(text string, maxWidth int) (head, tail string, width int) {
+	state := -1
+	rest := text
+	for rest != "" {
+		_, next, w, newState := uniseg.FirstGraphemeClusterInString(rest, state)
+		if width+w > maxWidth && width > 0 {
+			break
+		}
+		width += w
+		rest, state = next, newState
+	}
+	return text[:len(text)-len(rest)], rest, width
+}
+
+// dropLastCluster removes the last grapheme cluster from text, so backspace
+// deletes a whole character rather than one byte of it
+func dropLastCluster(text string) string {
+	g := uniseg.NewGraphemes(text)
+	last := 0
+	for g.Next() {
+		last, _ = g.Positions()
+	}
+	return text[:last]
+}
+
+// truncateText cuts text to maxWidth cells, ending it with an ellipsis if
+// anything was dropped
+func truncateText(text string, maxWidth int) string {
+	if textWidth(text) <= maxWidth {
+		return text
 	}
+	head, _, _ := cutText(text, max(1, maxWidth-1))
+	return head + "…"
 }
 
 func drawCenteredText(s tcell.Screen, x, y, w, h int, st tcell.Style, text string) {
 	lines := strings.Split(text, "\n")
 	cy := y + h/2 - len(lines)/2
 	for i, line := range lines {
-		cx := x + w/2 - len(line)/2
+		cx := x + w/2 - textWidth(line)/2
 		if cx < x {
 			cx = x
 		}
@@ -73,7 +127,7 @@ func wrapText(text string, maxWidth int) []string {
 	line := ""
 
 	for _, word := range words {
-		if len(line)+len(word)+1 > maxWidth {
+		if textWidth(line)+textWidth(word)+1 > maxWidth {
 			if line != "" {
 				lines = append(lines, line)
 				line = word
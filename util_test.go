@@ -0,0 +1,149 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	family = "\U0001F468\u200d\U0001F469\u200d\U0001F467" // man, woman, girl joined with ZWJ
+	flagUS = "\U0001F1FA\U0001F1F8"
+)
+
+func TestTextWidth(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"e\u0301", 1},
+		{"cafe\u0301", 4},
+		{"漢字", 4},
+		{"👍", 2},
+		{family, 2},
+		{flagUS, 2},
+		{"a漢e\u0301👍", 6},
+	}
+	for _, tt := range tests {
+		if got := textWidth(tt.text); got != tt.want {
+			t.Errorf("textWidth(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCutText(t *testing.T) {
+	tests := []struct {
+		text       string
+		maxWidth   int
+		head, tail string
+		width      int
+	}{
+		{"hello", 3, "hel", "lo", 3},
+		{"hello", 10, "hello", "", 5},
+		{"e\u0301e\u0301e\u0301", 2, "e\u0301e\u0301", "e\u0301", 2},
+		{"漢字", 2, "漢", "字", 2},
+		// a wide glyph that would straddle the boundary moves to the tail
+		{"a漢", 2, "a", "漢", 1},
+		{"ab👍c", 3, "ab", "👍c", 2},
+		{family + "x", 2, family, "x", 2},
+		{family + "x", 1, family, "x", 2}, // one cluster is always taken
+		{"漢", 0, "漢", "", 2},
+	}
+	for _, tt := range tests {
+		head, tail, width := cutText(tt.text, tt.maxWidth)
+		if head != tt.head || tail != tt.tail || width != tt.width {
+			t.Errorf("cutText(%q, %d) = %q, %q, %d, want %q, %q, %d",
+				tt.text, tt.maxWidth, head, tail, width, tt.head, tt.tail, tt.width)
+		}
+	}
+}
+
+func TestDropLastCluster(t *testing.T) {
+	tests := []struct{ text, want string }{
+		{"", ""},
+		{"ab", "a"},
+		{"cafe\u0301", "caf"},
+		{"a漢", "a"},
+		{"hi" + family, "hi"},
+		{"x" + flagUS, "x"},
+	}
+	for _, tt := range tests {
+		if got := dropLastCluster(tt.text); got != tt.want {
+			t.Errorf("dropLastCluster(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		text     string
+		maxWidth int
+		want     string
+	}{
+		{"hello", 5, "hello"},
+		{"hello", 4, "hel…"},
+		{"漢字漢字", 5, "漢字…"},
+		{"漢字漢字", 4, "漢…"},
+		{"e\u0301e\u0301e\u0301", 2, "e\u0301…"},
+	}
+	for _, tt := range tests {
+		if got := truncateText(tt.text, tt.maxWidth); got != tt.want {
+			t.Errorf("truncateText(%q, %d) = %q, want %q", tt.text, tt.maxWidth, got, tt.want)
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		text     string
+		maxWidth int
+		want     []string
+	}{
+		{"one two three", 7, []string{"one two", "three"}},
+		{"漢字 漢字 漢字", 9, []string{"漢字 漢字", "漢字"}},
+		{"cafe\u0301 cafe\u0301", 9, []string{"cafe\u0301 cafe\u0301"}},
+		{"👍👍 👍", 5, []string{"👍👍", "👍"}},
+	}
+	for _, tt := range tests {
+		if got := wrapText(tt.text, tt.maxWidth); !slices.Equal(got, tt.want) {
+			t.Errorf("wrapText(%q, %d) = %q, want %q", tt.text, tt.maxWidth, got, tt.want)
+		}
+	}
+}
+
+func TestDrawText(t *testing.T) {
+	tests := []struct {
+		text  string
+		cells []string // main rune plus combining runes of each cell, "" for the cell a wide glyph covers
+		end   int
+	}{
+		{"ab", []string{"a", "b"}, 2},
+		{"e\u0301x", []string{"e\u0301", "x"}, 2},
+		{"漢x", []string{"漢", "", "x"}, 3},
+		{family + "x", []string{family, "", "x"}, 3},
+	}
+	for _, tt := range tests {
+		s := tcell.NewSimulationScreen("")
+		if err := s.Init(); err != nil {
+			t.Fatal(err)
+		}
+		s.SetSize(10, 1)
+		if end := drawText(s, 0, 0, tcell.StyleDefault, tt.text); end != tt.end {
+			t.Errorf("drawText(%q) ended at %d, want %d", tt.text, end, tt.end)
+		}
+		s.Show()
+		cells, _, _ := s.GetContents()
+		for x, want := range tt.cells {
+			if want == "" {
+				continue
+			}
+			if got := string(cells[x].Runes); got != want {
+				t.Errorf("drawText(%q): cell %d is %q, want %q", tt.text, x, got, want)
+			}
+		}
+		s.Fini()
+	}
+}